@@ -0,0 +1,299 @@
+// Package config holds the application settings. They are read from a JSON
+// file in the XDG config dir, then environment variables, then command line
+// flags, each overriding the one before; see Load.
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Settings, set by Load. Until Load runs they hold the defaults with the
+// environment applied.
+var (
+	IMDB_API_URL string
+
+	META_SOURCE    string
+	META_ADDON_URL string
+
+	HTTP_TIMEOUT time.Duration
+	USER_AGENT   string
+	RATE_LIMIT   float64
+	RATE_BURST   float64
+
+	CACHE_DIR    string
+	DOWNLOAD_DIR string
+
+	QUALITY_PROFILES string
+	QUALITY_PROFILE  string
+
+	PLAYER      Player
+	DEBRID      Debrid
+	TORRENT     TorrentClient
+	KEYBINDINGS map[string]string // action -> key
+	THEME       Theme
+
+	// File is the config file Load read, or would have read if it existed
+	File string
+)
+
+// The addon list changes while the program runs, so it is only reached
+// through Addons, EnabledAddons and SaveAddons.
+var (
+	addonsMu sync.RWMutex
+	addons   []Addon
+	// addonsOverridden is set when ALC_ADDON_URL or --addons replaced the
+	// list from the file
+	addonsOverridden bool
+)
+
+// Config is the layout of the config file. Keys left out of the file keep
+// their defaults.
+type Config struct {
+	ImdbApiUrl   string            `json:"imdb_api_url"`
+	Addons       []Addon           `json:"addons"`
+	MetaSource   string            `json:"meta_source"`
+	MetaAddonUrl string            `json:"meta_addon_url"`
+	UserAgent    string            `json:"user_agent"`
+	CacheDir     string            `json:"cache_dir"`
+	DownloadDir  string            `json:"download_dir"`
+	Quality      Quality           `json:"quality"`
+	Player       Player            `json:"player"`
+	Debrid       Debrid            `json:"debrid"`
+	Torrent      TorrentClient     `json:"torrent_client"`
+	Keybindings  map[string]string `json:"keybindings"`
+	Theme        Theme             `json:"theme"`
+	Limits       Limits            `json:"limits"`
+
+	addonsOverridden bool
+}
+
+// Addon is an installed Stremio addon. Disabled addons are kept in the list
+// but not queried.
+type Addon struct {
+	Url      string `json:"url"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+// Quality selects the quality profiles file and the active profile. The
+// profiles themselves live in their own file (see the quality package),
+// which Validate loads to check it and the profile name.
+type Quality struct {
+	ProfilesFile string `json:"profiles_file"`
+	Profile      string `json:"profile"`
+}
+
+// Player is the video player streams and downloads are opened with.
+type Player struct {
+	Name    string   `json:"name"`    // mpv, vlc or command
+	Path    string   `json:"path"`    // binary, when not on PATH under Name
+	Args    []string `json:"args"`    // extra arguments before the stream's own
+	Command string   `json:"command"` // shell command with {url}, {title} and {sub}
+}
+
+// Binary returns the player executable.
+func (p Player) Binary() string {
+	if p.Path != "" {
+		return p.Path
+	}
+	return p.Name
+}
+
+// Debrid is the debrid service torrent streams are resolved through. An
+// empty Service leaves torrents to the torrent client.
+type Debrid struct {
+	Service string `json:"service"`  // realdebrid, alldebrid
+	ApiKey  string `json:"api_key"`  // API token from the service's account page
+	BaseUrl string `json:"base_url"` // API root, when not the service's own
+}
+
+// TorrentClient is where torrent streams go when no debrid service is set
+// up. An empty Client opens magnets with the system handler.
+type TorrentClient struct {
+	Client   string `json:"client"` // qbittorrent, transmission, command
+	Url      string `json:"url"`    // Web UI / RPC address
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Command  string `json:"command"`   // shell command with {magnet}, {hash} and {name}
+	SavePath string `json:"save_path"` // download directory in the client
+}
+
+// Theme sets the interface colors, as "#rrggbb" or ANSI 256 color numbers.
+type Theme struct {
+	Primary   string `json:"primary"`
+	Secondary string `json:"secondary"`
+	Accent    string `json:"accent"`
+	Error     string `json:"error"`
+	Muted     string `json:"muted"`
+}
+
+// Limits bounds network use.
+type Limits struct {
+	HTTPTimeout Duration `json:"http_timeout"`
+	RateLimit   float64  `json:"rate_limit"` // requests per second per host
+	RateBurst   float64  `json:"rate_burst"`
+}
+
+// Duration reads "15s" style strings, or a number of seconds.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err == nil {
+		*d = Duration(seconds * float64(time.Second))
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(text)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Defaults returns the built-in settings.
+func Defaults() Config {
+	return Config{
+		ImdbApiUrl:   "https://api.imdbapi.dev",
+		MetaSource:   "imdb",
+		MetaAddonUrl: "https://v3-cinemeta.strem.io/manifest.json",
+		UserAgent:    "stremio-tui",
+		DownloadDir:  "downloads",
+		Player:       Player{Name: "mpv"},
+		Keybindings:  map[string]string{},
+		Theme: Theme{
+			Primary:   "#7C3AED",
+			Secondary: "#A78BFA",
+			Accent:    "#10B981",
+			Error:     "#EF4444",
+			Muted:     "#6B7280",
+		},
+		Limits: Limits{
+			HTTPTimeout: Duration(15 * time.Second),
+			RateLimit:   2,
+			RateBurst:   4,
+		},
+	}
+}
+
+func init() {
+	c := Defaults()
+	c.applyEnv()
+	c.set()
+}
+
+// applyEnv overrides settings with the environment variables that are set.
+func (c *Config) applyEnv() {
+	setString := func(key string, dst *string) {
+		if v := os.Getenv(key); v != "" {
+			*dst = v
+		}
+	}
+	setString("IMDB_API_URL", &c.ImdbApiUrl)
+	setString("META_SOURCE", &c.MetaSource)
+	setString("META_ADDON_URL", &c.MetaAddonUrl)
+	setString("USER_AGENT", &c.UserAgent)
+	setString("CACHE_DIR", &c.CacheDir)
+	setString("DOWNLOAD_DIR", &c.DownloadDir)
+	setString("QUALITY_PROFILES", &c.Quality.ProfilesFile)
+	setString("QUALITY_PROFILE", &c.Quality.Profile)
+	setString("PLAYER", &c.Player.Name)
+	setString("PLAYER_COMMAND", &c.Player.Command)
+	setString("DEBRID_SERVICE", &c.Debrid.Service)
+	setString("DEBRID_API_KEY", &c.Debrid.ApiKey)
+	setString("DEBRID_BASE_URL", &c.Debrid.BaseUrl)
+	setString("TORRENT_CLIENT", &c.Torrent.Client)
+	setString("TORRENT_CLIENT_URL", &c.Torrent.Url)
+	setString("TORRENT_CLIENT_USERNAME", &c.Torrent.Username)
+	setString("TORRENT_CLIENT_PASSWORD", &c.Torrent.Password)
+	setString("TORRENT_COMMAND", &c.Torrent.Command)
+
+	if v := os.Getenv("ALC_ADDON_URL"); v != "" {
+		c.Addons = ParseAddons(v)
+		c.addonsOverridden = true
+	}
+	if d, err := time.ParseDuration(os.Getenv("HTTP_TIMEOUT")); err == nil {
+		c.Limits.HTTPTimeout = Duration(d)
+	}
+	if f, err := strconv.ParseFloat(os.Getenv("RATE_LIMIT"), 64); err == nil && f > 0 {
+		c.Limits.RateLimit = f
+	}
+	if f, err := strconv.ParseFloat(os.Getenv("RATE_BURST"), 64); err == nil && f > 0 {
+		c.Limits.RateBurst = f
+	}
+}
+
+// ParseAddons reads addon URLs separated by commas or whitespace.
+func ParseAddons(list string) []Addon {
+	var addons []Addon
+	for _, u := range strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	}) {
+		addons = append(addons, Addon{Url: u})
+	}
+	return addons
+}
+
+// set publishes the settings to the package variables.
+func (c Config) set() {
+	IMDB_API_URL = strings.TrimSuffix(c.ImdbApiUrl, "/")
+	addonsMu.Lock()
+	addons, addonsOverridden = c.Addons, c.addonsOverridden
+	addonsMu.Unlock()
+	META_SOURCE = c.MetaSource
+	META_ADDON_URL = c.MetaAddonUrl
+	HTTP_TIMEOUT = time.Duration(c.Limits.HTTPTimeout)
+	USER_AGENT = c.UserAgent
+	RATE_LIMIT = c.Limits.RateLimit
+	RATE_BURST = c.Limits.RateBurst
+	CACHE_DIR = c.CacheDir
+	DOWNLOAD_DIR = c.DownloadDir
+	QUALITY_PROFILES = c.Quality.ProfilesFile
+	QUALITY_PROFILE = c.Quality.Profile
+	PLAYER = c.Player
+	DEBRID = c.Debrid
+	TORRENT = c.Torrent
+	KEYBINDINGS = c.Keybindings
+	THEME = c.Theme
+}
+
+// Addons returns a copy of the installed addons, in order.
+func Addons() []Addon {
+	addonsMu.RLock()
+	defer addonsMu.RUnlock()
+	return append([]Addon{}, addons...)
+}
+
+// AddonsOverridden reports whether ALC_ADDON_URL or --addons replaced the
+// addons in the config file, in which case SaveAddons doesn't write them.
+func AddonsOverridden() bool {
+	addonsMu.RLock()
+	defer addonsMu.RUnlock()
+	return addonsOverridden
+}
+
+// EnabledAddons returns the URLs of the addons to query, in order.
+func EnabledAddons() []string {
+	addonsMu.RLock()
+	defer addonsMu.RUnlock()
+	var urls []string
+	for _, a := range addons {
+		if !a.Disabled {
+			urls = append(urls, a.Url)
+		}
+	}
+	return urls
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// Key scopes are the views and tabs a binding applies in.
+const (
+	ScopeSearch       = "search"
+	ScopeResults      = "results"
+	ScopeDetail       = "detail"
+	ScopeCatalogs     = "catalogs"
+	ScopeCatalogItems = "catalog items"
+	ScopeSeasons      = "seasons"
+	ScopeEpisodes     = "episodes"
+	ScopeStreams      = "streams"
+	ScopeBatchSelect  = "batch select"
+	ScopeDownloads    = "downloads"
+	ScopeAddons       = "addons"
+)
+
+// DefaultKeys lists the actions that can be rebound and their default keys.
+var DefaultKeys = map[string]string{
+	"quit":            "q",
+	"play":            "p",
+	"download":        "d",
+	"play_best":       "P",
+	"download_best":   "D",
+	"subtitles":       "s",
+	"sort":            "o",
+	"reverse_sort":    "O",
+	"cycle_profile":   "Q",
+	"filter":          "/",
+	"search_filters":  "f",
+	"load_more":       "n",
+	"next_genre":      "g",
+	"batch":           "b",
+	"episodes":        "e",
+	"trailer":         "t",
+	"watchlist":       "w",
+	"cancel_download": "x",
+	"refresh":         "ctrl+r",
+	"browse":          "ctrl+b",
+	"show_watchlist":  "ctrl+w",
+}
+
+// KeyScopes lists the scopes each action exists in. A binding only takes
+// over its key there.
+var KeyScopes = map[string][]string{
+	"quit": {ScopeSearch, ScopeResults, ScopeDetail, ScopeCatalogs, ScopeCatalogItems,
+		ScopeSeasons, ScopeEpisodes, ScopeStreams, ScopeBatchSelect, ScopeDownloads, ScopeAddons},
+	"play":            {ScopeStreams, ScopeDownloads},
+	"download":        {ScopeStreams},
+	"play_best":       {ScopeResults, ScopeDetail, ScopeCatalogItems, ScopeEpisodes},
+	"download_best":   {ScopeResults, ScopeDetail, ScopeCatalogItems, ScopeEpisodes},
+	"subtitles":       {ScopeStreams},
+	"sort":            {ScopeStreams},
+	"reverse_sort":    {ScopeStreams},
+	"cycle_profile":   {ScopeStreams},
+	"filter":          {ScopeEpisodes, ScopeStreams},
+	"search_filters":  {ScopeSearch, ScopeResults},
+	"load_more":       {ScopeResults, ScopeCatalogItems},
+	"next_genre":      {ScopeCatalogItems},
+	"batch":           {ScopeEpisodes},
+	"episodes":        {ScopeDetail},
+	"trailer":         {ScopeDetail},
+	"watchlist":       {ScopeDetail},
+	"cancel_download": {ScopeDownloads},
+	"refresh": {ScopeSearch, ScopeResults, ScopeDetail, ScopeCatalogs, ScopeCatalogItems,
+		ScopeSeasons, ScopeEpisodes, ScopeStreams, ScopeAddons},
+	"browse":         {ScopeSearch},
+	"show_watchlist": {ScopeSearch},
+}
+
+// searchKeys are the default keys in the search box, where letters are
+// typed into the query.
+var searchKeys = map[string]string{
+	"quit":           "ctrl+c",
+	"search_filters": "ctrl+f",
+}
+
+// fixedKeys are the keys each scope handles that can't be rebound:
+// navigation, and what the search box uses for editing.
+var fixedKeys = map[string][]string{
+	ScopeSearch:       {"ctrl+a", "ctrl+d", "ctrl+e", "ctrl+h", "ctrl+k", "ctrl+u"},
+	ScopeResults:      {"j", "k", "ctrl+f"},
+	ScopeDetail:       {"s"},
+	ScopeCatalogs:     {"j", "k"},
+	ScopeCatalogItems: {"j", "k"},
+	ScopeSeasons:      {"j", "k"},
+	ScopeEpisodes:     {"j", "k"},
+	ScopeStreams:      {"j", "k", " ", "c"},
+	ScopeBatchSelect:  {"j", "k", " ", "a", "n"},
+	ScopeDownloads:    {"j", "k"},
+	ScopeAddons:       {"j", "k", " ", "a", "x", "J", "K"},
+}
+
+// globalKeys are reserved everywhere. ctrl+i and ctrl+m arrive as tab and
+// enter.
+var globalKeys = []string{"ctrl+c", "ctrl+i", "ctrl+m"}
+
+// DefaultKey returns the key action's handler matches in scope.
+func DefaultKey(scope, action string) string {
+	if key, ok := searchKeys[action]; ok && scope == ScopeSearch {
+		return key
+	}
+	return DefaultKeys[action]
+}
+
+// IsTextKey reports whether key types a character, so it can't be bound
+// in the search box.
+func IsTextKey(key string) bool {
+	return utf8.RuneCountInString(key) == 1
+}
+
+// validKey reports whether key is a single character or ctrl+a to ctrl+z.
+func validKey(key string) bool {
+	if IsTextKey(key) {
+		return true
+	}
+	return len(key) == len("ctrl+a") && key[:5] == "ctrl+" && key[5] >= 'a' && key[5] <= 'z'
+}
+
+// validateKeybindings rejects keys that can't be bound and bindings that
+// take a key another action, or navigation, already uses in the same scope.
+func validateKeybindings(bindings map[string]string) []error {
+	actions := make([]string, 0, len(bindings))
+	for action := range bindings {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	var errs []error
+	// owners maps each scope's keys to the action using them, or "" for
+	// fixed keys
+	owners := map[string]map[string]string{}
+	for scope, keys := range fixedKeys {
+		owners[scope] = map[string]string{}
+		for _, key := range keys {
+			owners[scope][key] = ""
+		}
+		for _, key := range globalKeys {
+			owners[scope][key] = ""
+		}
+	}
+	for action := range DefaultKeys {
+		for _, scope := range KeyScopes[action] {
+			owners[scope][DefaultKey(scope, action)] = action
+		}
+	}
+
+	for _, action := range actions {
+		key := bindings[action]
+		if _, ok := DefaultKeys[action]; !ok {
+			errs = append(errs, fmt.Errorf("keybindings: unknown action %q", action))
+			continue
+		}
+		if !validKey(key) {
+			errs = append(errs, fmt.Errorf("keybindings.%s: %q is not a single character or ctrl+a to ctrl+z", action, key))
+			continue
+		}
+		if scopes := KeyScopes[action]; len(scopes) == 1 && scopes[0] == ScopeSearch && IsTextKey(key) {
+			errs = append(errs, fmt.Errorf("keybindings.%s: must be a ctrl+ key, it is pressed in the search box", action))
+			continue
+		}
+		for _, scope := range KeyScopes[action] {
+			if scope == ScopeSearch && IsTextKey(key) {
+				continue
+			}
+			owner, taken := owners[scope][key]
+			if taken && owner != action {
+				if owner == "" {
+					errs = append(errs, fmt.Errorf("keybindings.%s: %q is reserved in the %s view", action, key, scope))
+				} else {
+					errs = append(errs, fmt.Errorf("keybindings.%s: %q is already used by %s in the %s view", action, key, owner, scope))
+				}
+				break
+			}
+			owners[scope][key] = action
+		}
+	}
+	return errs
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/rshero/stremio-tui/quality"
+)
+
+// Flags are the command line overrides. Empty values leave the setting alone.
+type Flags struct {
+	Config      *string
+	Addons      *string
+	ImdbApiUrl  *string
+	MetaSource  *string
+	DownloadDir *string
+	Player      *string
+	Profile     *string
+}
+
+// RegisterFlags adds the config flags to fs.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	return &Flags{
+		Config:      fs.String("config", "", "config file (default $XDG_CONFIG_HOME/stremio-tui/config.json)"),
+		Addons:      fs.String("addons", "", "comma-separated addon manifest URLs, replacing the configured ones"),
+		ImdbApiUrl:  fs.String("imdb-api-url", "", "IMDB API base URL"),
+		MetaSource:  fs.String("meta-source", "", "where seasons and episodes come from: imdb or addon"),
+		DownloadDir: fs.String("download-dir", "", "directory downloads are saved to"),
+		Player:      fs.String("player", "", "video player: mpv, vlc or command"),
+		Profile:     fs.String("profile", "", "quality profile to use"),
+	}
+}
+
+// DefaultPath is $XDG_CONFIG_HOME/stremio-tui/config.json.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "stremio-tui", "config.json"), nil
+}
+
+// Load builds the settings from, in increasing priority: the defaults, the
+// config file, environment variables and flags (nil for none). The file is
+// the --config flag, else STREMIO_TUI_CONFIG, else DefaultPath; a missing
+// file at the default path is not an error. Settings are only changed when
+// the result is valid.
+func Load(flags *Flags) error {
+	c := Defaults()
+
+	path, explicit := os.Getenv("STREMIO_TUI_CONFIG"), true
+	if flags != nil && *flags.Config != "" {
+		path = *flags.Config
+	}
+	if path == "" {
+		explicit = false
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return err
+		}
+	}
+	if err := c.readFile(path, explicit); err != nil {
+		return err
+	}
+
+	c.applyEnv()
+	if flags != nil {
+		c.applyFlags(flags)
+	}
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	File = path
+	c.set()
+	return nil
+}
+
+// readFile merges the config file into c.
+func (c *Config) readFile(path string, mustExist bool) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !mustExist {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *Config) applyFlags(f *Flags) {
+	set := func(v *string, dst *string) {
+		if v != nil && *v != "" {
+			*dst = *v
+		}
+	}
+	if f.Addons != nil && *f.Addons != "" {
+		c.Addons = ParseAddons(*f.Addons)
+		c.addonsOverridden = true
+	}
+	set(f.ImdbApiUrl, &c.ImdbApiUrl)
+	set(f.MetaSource, &c.MetaSource)
+	set(f.DownloadDir, &c.DownloadDir)
+	set(f.Player, &c.Player.Name)
+	set(f.Profile, &c.Quality.Profile)
+}
+
+var colorRe = regexp.MustCompile(`^(#[0-9a-fA-F]{6}|#[0-9a-fA-F]{3}|[0-9]{1,3})$`)
+
+// Validate reports every invalid setting at once.
+func (c Config) Validate() error {
+	var errs []error
+	if err := validateUrl(c.ImdbApiUrl, "http", "https"); err != nil {
+		errs = append(errs, fmt.Errorf("imdb_api_url: %w", err))
+	}
+	for i, a := range c.Addons {
+		if err := ValidateAddonUrl(a.Url); err != nil {
+			errs = append(errs, fmt.Errorf("addons[%d]: %w", i, err))
+		}
+	}
+	if c.MetaAddonUrl != "" {
+		if err := validateUrl(c.MetaAddonUrl, "http", "https", "stremio"); err != nil {
+			errs = append(errs, fmt.Errorf("meta_addon_url: %w", err))
+		}
+	}
+	if c.MetaSource != "imdb" && c.MetaSource != "addon" {
+		errs = append(errs, fmt.Errorf("meta_source: must be imdb or addon, not %q", c.MetaSource))
+	}
+	if c.DownloadDir == "" {
+		errs = append(errs, fmt.Errorf("download_dir: must not be empty"))
+	}
+	switch c.Player.Name {
+	case "mpv", "vlc":
+	case "command":
+		if c.Player.Command == "" {
+			errs = append(errs, fmt.Errorf("player.command: required for the command player"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("player.name: must be mpv, vlc or command, not %q", c.Player.Name))
+	}
+	switch c.Debrid.Service {
+	case "":
+	case "realdebrid", "alldebrid":
+		if c.Debrid.ApiKey == "" {
+			errs = append(errs, fmt.Errorf("debrid.api_key: required for %s", c.Debrid.Service))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("debrid.service: must be realdebrid or alldebrid, not %q", c.Debrid.Service))
+	}
+	if c.Debrid.BaseUrl != "" {
+		if err := validateUrl(c.Debrid.BaseUrl, "http", "https"); err != nil {
+			errs = append(errs, fmt.Errorf("debrid.base_url: %w", err))
+		}
+	}
+	switch c.Torrent.Client {
+	case "", "qbittorrent", "transmission":
+	case "command":
+		if c.Torrent.Command == "" {
+			errs = append(errs, fmt.Errorf("torrent_client.command: required for the command client"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("torrent_client.client: must be qbittorrent, transmission or command, not %q", c.Torrent.Client))
+	}
+	if c.Torrent.Url != "" {
+		if err := validateUrl(c.Torrent.Url, "http", "https"); err != nil {
+			errs = append(errs, fmt.Errorf("torrent_client.url: %w", err))
+		}
+	}
+	if profiles, err := quality.Load(c.Quality.ProfilesFile); err != nil {
+		errs = append(errs, fmt.Errorf("quality.profiles_file: %w", err))
+	} else if c.Quality.Profile != "" && quality.Find(profiles, c.Quality.Profile) < 0 {
+		errs = append(errs, fmt.Errorf("quality.profile: no profile named %q", c.Quality.Profile))
+	}
+	errs = append(errs, validateKeybindings(c.Keybindings)...)
+	for name, color := range map[string]string{
+		"primary": c.Theme.Primary, "secondary": c.Theme.Secondary, "accent": c.Theme.Accent,
+		"error": c.Theme.Error, "muted": c.Theme.Muted,
+	} {
+		if !colorRe.MatchString(color) {
+			errs = append(errs, fmt.Errorf("theme.%s: %q is not a #rrggbb or ANSI color", name, color))
+		}
+	}
+	if c.Limits.HTTPTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("limits.http_timeout: must be positive"))
+	}
+	if c.Limits.RateLimit <= 0 || c.Limits.RateBurst <= 0 {
+		errs = append(errs, fmt.Errorf("limits: rate_limit and rate_burst must be positive"))
+	}
+	return errors.Join(errs...)
+}
+
+// validateUrl checks that s is an absolute URL with one of the schemes.
+func validateUrl(s string, schemes ...string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%q has no host", s)
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q must use %v", s, schemes)
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// ValidateAddonUrl checks an addon URL the way Validate checks the
+// configured ones.
+func ValidateAddonUrl(u string) error {
+	return validateUrl(u, "http", "https", "stremio")
+}
+
+// SaveAddons replaces the addon list and writes it to the config file,
+// leaving the file's other keys and its permissions as they are. The new
+// list is used even when the file can't be written. A list that came from
+// ALC_ADDON_URL or --addons only changes for this run.
+func SaveAddons(list []Addon) error {
+	list = append([]Addon{}, list...)
+	addonsMu.Lock()
+	addons = list
+	overridden := addonsOverridden
+	addonsMu.Unlock()
+	if overridden {
+		return nil
+	}
+
+	path := File
+	if path == "" {
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return err
+		}
+	}
+
+	raw := map[string]json.RawMessage{}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	}
+
+	if raw["addons"], err = json.Marshal(list); err != nil {
+		return err
+	}
+	if data, err = json.MarshalIndent(raw, "", "  "); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// The file may hold API keys and passwords, so a new one is private
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), mode); err != nil {
+		return err
+	}
+	// WriteFile leaves the mode of an existing file alone and applies the umask
+	if err := os.Chmod(tmp, mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,157 @@
+package debrid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const allDebridUrl = "https://api.alldebrid.com/v4"
+
+// allDebridReady is the magnet statusCode of a finished torrent; lower codes
+// are still in progress and higher ones are errors.
+const allDebridReady = 4
+
+// AllDebrid is an AllDebrid account.
+type AllDebrid struct {
+	client
+}
+
+// NewAllDebrid creates an AllDebrid client for the API at base (the public
+// API when empty), authenticated with the account's API key.
+func NewAllDebrid(base, apiKey string, timeout time.Duration) *AllDebrid {
+	if base == "" {
+		base = allDebridUrl
+	}
+	return &AllDebrid{newClient(base, apiKey, timeout)}
+}
+
+func (ad *AllDebrid) Name() string { return "AllDebrid" }
+
+// call performs a request and unwraps the {status, data, error} envelope
+// into data.
+func (ad *AllDebrid) call(ctx context.Context, path string, query url.Values, data any) error {
+	query.Set("agent", "stremio-tui")
+	var response struct {
+		Status string          `json:"status"`
+		Data   json.RawMessage `json:"data"`
+		Error  struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := ad.do(ctx, http.MethodGet, path+"?"+query.Encode(), nil, &response); err != nil {
+		return err
+	}
+	if response.Status != "success" {
+		return fmt.Errorf("%s (%s)", response.Error.Message, response.Error.Code)
+	}
+	return json.Unmarshal(response.Data, data)
+}
+
+func (ad *AllDebrid) Cached(ctx context.Context, hashes []string) (map[string]bool, error) {
+	var data struct {
+		Magnets []struct {
+			Hash    string `json:"hash"`
+			Instant bool   `json:"instant"`
+		} `json:"magnets"`
+	}
+	if err := ad.call(ctx, "/magnet/instant", url.Values{"magnets[]": hashes}, &data); err != nil {
+		return nil, err
+	}
+
+	cached := map[string]bool{}
+	for _, m := range data.Magnets {
+		if m.Instant {
+			cached[strings.ToLower(m.Hash)] = true
+		}
+	}
+	return cached, nil
+}
+
+// Find looks through the account's magnets. Errored ones are skipped.
+func (ad *AllDebrid) Find(ctx context.Context, hash string) (string, error) {
+	var data struct {
+		Magnets []struct {
+			Id         int    `json:"id"`
+			Hash       string `json:"hash"`
+			StatusCode int    `json:"statusCode"`
+		} `json:"magnets"`
+	}
+	if err := ad.call(ctx, "/magnet/status", url.Values{}, &data); err != nil {
+		return "", err
+	}
+	for _, m := range data.Magnets {
+		if strings.EqualFold(m.Hash, hash) && m.StatusCode <= allDebridReady {
+			return strconv.Itoa(m.Id), nil
+		}
+	}
+	return "", nil
+}
+
+func (ad *AllDebrid) AddMagnet(ctx context.Context, magnet string) (string, error) {
+	var data struct {
+		Magnets []struct {
+			Id    int `json:"id"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		} `json:"magnets"`
+	}
+	if err := ad.call(ctx, "/magnet/upload", url.Values{"magnets[]": {magnet}}, &data); err != nil {
+		return "", err
+	}
+	if len(data.Magnets) == 0 {
+		return "", fmt.Errorf("magnet wasn't added")
+	}
+	if e := data.Magnets[0].Error; e != nil {
+		return "", fmt.Errorf("%s", e.Message)
+	}
+	return strconv.Itoa(data.Magnets[0].Id), nil
+}
+
+// Link unlocks the link of the chosen file once the magnet is ready.
+// AllDebrid lists a link per file, in torrent order.
+func (ad *AllDebrid) Link(ctx context.Context, id string, fileIdx *int) (string, error) {
+	var data struct {
+		Magnets struct {
+			Status     string `json:"status"`
+			StatusCode int    `json:"statusCode"`
+			Links      []struct {
+				Link string `json:"link"`
+				Size int64  `json:"size"`
+			} `json:"links"`
+		} `json:"magnets"`
+	}
+	if err := ad.call(ctx, "/magnet/status", url.Values{"id": {id}}, &data); err != nil {
+		return "", err
+	}
+
+	magnet := data.Magnets
+	switch {
+	case magnet.StatusCode < allDebridReady:
+		return "", ErrNotReady
+	case magnet.StatusCode > allDebridReady:
+		return "", fmt.Errorf("torrent status %q", magnet.Status)
+	case len(magnet.Links) == 0:
+		return "", fmt.Errorf("torrent has no links")
+	}
+
+	sizes := make([]int64, len(magnet.Links))
+	for i, l := range magnet.Links {
+		sizes[i] = l.Size
+	}
+	var unlocked struct {
+		Link string `json:"link"`
+	}
+	link := magnet.Links[pickFile(sizes, fileIdx)].Link
+	if err := ad.call(ctx, "/link/unlock", url.Values{"link": {link}}, &unlocked); err != nil {
+		return "", err
+	}
+	return unlocked.Link, nil
+}
@@ -0,0 +1,189 @@
+// Package debrid turns torrent streams into direct HTTP links through a
+// debrid service, which fetches the torrent on its servers and serves the
+// files over HTTP.
+package debrid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	config "github.com/rshero/stremio-tui/config"
+	apiutils "github.com/rshero/stremio-tui/utils"
+)
+
+var (
+	// ErrNotCached means the service doesn't have the torrent yet, so it
+	// can't be streamed straight away.
+	ErrNotCached = errors.New("torrent isn't cached by the debrid service")
+
+	// ErrNotReady means the torrent was added but the service is still
+	// preparing it.
+	ErrNotReady = errors.New("torrent isn't ready yet")
+
+	// errOtherFile means a torrent already in the account doesn't have the
+	// wanted file selected, so it is added again.
+	errOtherFile = errors.New("file isn't selected in the torrent")
+)
+
+// pollInterval is how often Resolve asks whether an added torrent is ready.
+var pollInterval = 2 * time.Second
+
+// Service is a debrid service account.
+type Service interface {
+	Name() string
+
+	// Cached reports which of the info hashes the service can serve
+	// without downloading them first. Hashes are lower case.
+	Cached(ctx context.Context, hashes []string) (map[string]bool, error)
+
+	// Find returns the id of a torrent with the info hash that is already
+	// in the account, or "" when there is none.
+	Find(ctx context.Context, hash string) (string, error)
+
+	// AddMagnet adds a torrent to the account and returns its id.
+	AddMagnet(ctx context.Context, magnet string) (string, error)
+
+	// Link picks file fileIdx of the torrent (the largest file when nil or
+	// out of range) and returns a direct HTTP URL for it, or ErrNotReady
+	// while the service is still working on it.
+	Link(ctx context.Context, id string, fileIdx *int) (string, error)
+}
+
+// New creates the service described by cfg, or returns nil when none is
+// configured.
+func New(cfg config.Debrid, timeout time.Duration) (Service, error) {
+	switch cfg.Service {
+	case "":
+		return nil, nil
+	case "realdebrid":
+		return NewRealDebrid(cfg.BaseUrl, cfg.ApiKey, timeout), nil
+	case "alldebrid":
+		return NewAllDebrid(cfg.BaseUrl, cfg.ApiKey, timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown debrid service %q", cfg.Service)
+	}
+}
+
+// Resolve turns a torrent into a direct URL: it checks the torrent is
+// cached, adds the magnet unless the account already has it and waits for
+// the service to hand out the file's link.
+func Resolve(ctx context.Context, svc Service, infoHash, magnet string, fileIdx *int) (string, error) {
+	hash := strings.ToLower(infoHash)
+	cached, err := svc.Cached(ctx, []string{hash})
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", svc.Name(), err)
+	}
+	if !cached[hash] {
+		return "", ErrNotCached
+	}
+
+	id, err := svc.Find(ctx, hash)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", svc.Name(), err)
+	}
+	if id != "" {
+		link, err := waitForLink(ctx, svc, id, fileIdx)
+		if !errors.Is(err, errOtherFile) {
+			return link, err
+		}
+	}
+
+	id, err = svc.AddMagnet(ctx, magnet)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", svc.Name(), err)
+	}
+	return waitForLink(ctx, svc, id, fileIdx)
+}
+
+// waitForLink polls the service until the torrent's link is ready.
+func waitForLink(ctx context.Context, svc Service, id string, fileIdx *int) (string, error) {
+	for {
+		link, err := svc.Link(ctx, id, fileIdx)
+		if !errors.Is(err, ErrNotReady) {
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", svc.Name(), err)
+			}
+			return link, nil
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// client sends the API requests of a service, authenticated with a bearer
+// token.
+type client struct {
+	http  *http.Client
+	base  string
+	token string
+}
+
+func newClient(base, token string, timeout time.Duration) client {
+	return client{
+		http:  &http.Client{Timeout: timeout},
+		base:  strings.TrimSuffix(base, "/"),
+		token: token,
+	}
+}
+
+// do sends a request to base+path and decodes the JSON response into v
+// (nil to ignore it). form, when not nil, is sent as the POST body.
+func (c client) do(ctx context.Context, method, path string, form url.Values, v any) error {
+	apiUrl := c.base + path
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, apiUrl, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if config.USER_AGENT != "" {
+		req.Header.Set("User-Agent", config.USER_AGENT)
+	}
+
+	r, err := c.http.Do(req)
+	if err != nil {
+		return &apiutils.NetworkError{Url: apiUrl, Err: err}
+	}
+	defer r.Body.Close()
+	if r.StatusCode < 200 || r.StatusCode > 299 {
+		return &apiutils.HTTPError{Url: apiUrl, StatusCode: r.StatusCode}
+	}
+	if v == nil || r.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return &apiutils.DecodeError{Url: apiUrl, Err: err}
+	}
+	return nil
+}
+
+// pickFile returns the index of file fileIdx, or of the largest file when
+// fileIdx is nil or out of range.
+func pickFile(sizes []int64, fileIdx *int) int {
+	if fileIdx != nil && *fileIdx >= 0 && *fileIdx < len(sizes) {
+		return *fileIdx
+	}
+	best := 0
+	for i, size := range sizes {
+		if size > sizes[best] {
+			best = i
+		}
+	}
+	return best
+}
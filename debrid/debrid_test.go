@@ -0,0 +1,233 @@
+package debrid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testHash = "0123456789abcdef0123456789abcdef01234567"
+
+func init() {
+	pollInterval = time.Millisecond
+}
+
+func intPtr(i int) *int { return &i }
+
+// fakeRealDebrid serves a torrent with three files, the second the largest.
+type fakeRealDebrid struct {
+	cached   bool
+	torrents map[string][]int // id -> selected file ids
+	order    []string
+	added    int
+}
+
+var realDebridFiles = []int64{100, 500, 200} // file ids 1, 2 and 3
+
+func (f *fakeRealDebrid) handler() http.Handler {
+	mux := http.NewServeMux()
+	writeJSON := func(w http.ResponseWriter, v any) {
+		json.NewEncoder(w).Encode(v)
+	}
+	mux.HandleFunc("GET /torrents/instantAvailability/{hash}", func(w http.ResponseWriter, r *http.Request) {
+		if f.cached {
+			writeJSON(w, map[string]any{r.PathValue("hash"): map[string]any{"rd": []any{map[string]any{}}}})
+		} else {
+			writeJSON(w, map[string]any{r.PathValue("hash"): []any{}})
+		}
+	})
+	mux.HandleFunc("GET /torrents", func(w http.ResponseWriter, r *http.Request) {
+		list := []map[string]string{}
+		for _, id := range f.order {
+			list = append(list, map[string]string{"id": id, "hash": testHash, "status": "downloaded"})
+		}
+		writeJSON(w, list)
+	})
+	mux.HandleFunc("POST /torrents/addMagnet", func(w http.ResponseWriter, r *http.Request) {
+		f.added++
+		id := fmt.Sprintf("T%d", len(f.order)+1)
+		f.torrents[id] = nil
+		f.order = append(f.order, id)
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, map[string]string{"id": id})
+	})
+	mux.HandleFunc("GET /torrents/info/{id}", func(w http.ResponseWriter, r *http.Request) {
+		selected, ok := f.torrents[r.PathValue("id")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		status := "downloaded"
+		if len(selected) == 0 {
+			status = "waiting_files_selection"
+		}
+		files := []map[string]any{}
+		links := []string{}
+		for i, size := range realDebridFiles {
+			sel := 0
+			for _, id := range selected {
+				if id == i+1 {
+					sel = 1
+					links = append(links, fmt.Sprintf("link%d", i+1))
+				}
+			}
+			files = append(files, map[string]any{"id": i + 1, "bytes": size, "selected": sel})
+		}
+		writeJSON(w, map[string]any{"status": status, "files": files, "links": links})
+	})
+	mux.HandleFunc("POST /torrents/selectFiles/{id}", func(w http.ResponseWriter, r *http.Request) {
+		file, _ := strconv.Atoi(r.FormValue("files"))
+		f.torrents[r.PathValue("id")] = []int{file}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /unrestrict/link", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]string{"download": "https://dl.example/" + r.FormValue("link")})
+	})
+	return mux
+}
+
+func TestRealDebridResolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		cached   bool
+		existing []int // selected files of a torrent already in the account
+		fileIdx  *int
+		want     string
+		added    int
+	}{
+		{name: "uncached", cached: false},
+		{name: "largest file", cached: true, want: "https://dl.example/link2", added: 1},
+		{name: "file index", cached: true, fileIdx: intPtr(2), want: "https://dl.example/link3", added: 1},
+		{name: "out of range index", cached: true, fileIdx: intPtr(7), want: "https://dl.example/link2", added: 1},
+		{name: "reuses torrent", cached: true, existing: []int{1, 2}, want: "https://dl.example/link2", added: 0},
+		{name: "other file selected", cached: true, existing: []int{3}, fileIdx: intPtr(0), want: "https://dl.example/link1", added: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeRealDebrid{cached: tt.cached, torrents: map[string][]int{}}
+			if tt.existing != nil {
+				fake.torrents["T1"] = tt.existing
+				fake.order = []string{"T1"}
+			}
+			srv := httptest.NewServer(fake.handler())
+			defer srv.Close()
+
+			link, err := Resolve(context.Background(), NewRealDebrid(srv.URL, "token", time.Second), testHash, "magnet:?xt=urn:btih:"+testHash, tt.fileIdx)
+			if !tt.cached {
+				if !errors.Is(err, ErrNotCached) {
+					t.Fatalf("err = %v, want ErrNotCached", err)
+				}
+				if fake.added != 0 {
+					t.Errorf("added %d torrents for an uncached hash", fake.added)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if link != tt.want {
+				t.Errorf("link = %q, want %q", link, tt.want)
+			}
+			if fake.added != tt.added {
+				t.Errorf("added %d torrents, want %d", fake.added, tt.added)
+			}
+		})
+	}
+}
+
+// fakeAllDebrid serves a magnet with two files, the second the largest.
+type fakeAllDebrid struct {
+	cached  bool
+	magnets []int
+	added   int
+}
+
+func (f *fakeAllDebrid) handler() http.Handler {
+	mux := http.NewServeMux()
+	reply := func(w http.ResponseWriter, data any) {
+		json.NewEncoder(w).Encode(map[string]any{"status": "success", "data": data})
+	}
+	mux.HandleFunc("GET /magnet/instant", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, map[string]any{"magnets": []any{map[string]any{"hash": r.FormValue("magnets[]"), "instant": f.cached}}})
+	})
+	mux.HandleFunc("GET /magnet/upload", func(w http.ResponseWriter, r *http.Request) {
+		f.added++
+		id := 100 + len(f.magnets)
+		f.magnets = append(f.magnets, id)
+		reply(w, map[string]any{"magnets": []any{map[string]any{"id": id}}})
+	})
+	mux.HandleFunc("GET /magnet/status", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("id") == "" {
+			list := []any{}
+			for _, id := range f.magnets {
+				list = append(list, map[string]any{"id": id, "hash": testHash, "statusCode": allDebridReady})
+			}
+			reply(w, map[string]any{"magnets": list})
+			return
+		}
+		reply(w, map[string]any{"magnets": map[string]any{
+			"status":     "Ready",
+			"statusCode": allDebridReady,
+			"links": []any{
+				map[string]any{"link": "file0", "size": 100},
+				map[string]any{"link": "file1", "size": 500},
+			},
+		}})
+	})
+	mux.HandleFunc("GET /link/unlock", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, map[string]any{"link": "https://dl.example/" + r.FormValue("link")})
+	})
+	return mux
+}
+
+func TestAllDebridResolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		cached   bool
+		existing bool
+		fileIdx  *int
+		want     string
+		added    int
+	}{
+		{name: "uncached", cached: false},
+		{name: "largest file", cached: true, want: "https://dl.example/file1", added: 1},
+		{name: "file index", cached: true, fileIdx: intPtr(0), want: "https://dl.example/file0", added: 1},
+		{name: "reuses magnet", cached: true, existing: true, want: "https://dl.example/file1", added: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeAllDebrid{cached: tt.cached}
+			if tt.existing {
+				fake.magnets = []int{100}
+			}
+			srv := httptest.NewServer(fake.handler())
+			defer srv.Close()
+
+			link, err := Resolve(context.Background(), NewAllDebrid(srv.URL, "key", time.Second), testHash, "magnet:?xt=urn:btih:"+testHash, tt.fileIdx)
+			if !tt.cached {
+				if !errors.Is(err, ErrNotCached) {
+					t.Fatalf("err = %v, want ErrNotCached", err)
+				}
+				if fake.added != 0 {
+					t.Errorf("added %d magnets for an uncached hash", fake.added)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if link != tt.want {
+				t.Errorf("link = %q, want %q", link, tt.want)
+			}
+			if fake.added != tt.added {
+				t.Errorf("added %d magnets, want %d", fake.added, tt.added)
+			}
+		})
+	}
+}
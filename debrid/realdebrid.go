@@ -0,0 +1,142 @@
+package debrid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const realDebridUrl = "https://api.real-debrid.com/rest/1.0"
+
+// RealDebrid is a Real-Debrid account.
+type RealDebrid struct {
+	client
+}
+
+// NewRealDebrid creates a Real-Debrid client for the API at base (the
+// public API when empty), authenticated with the account's API token.
+func NewRealDebrid(base, token string, timeout time.Duration) *RealDebrid {
+	if base == "" {
+		base = realDebridUrl
+	}
+	return &RealDebrid{newClient(base, token, timeout)}
+}
+
+func (rd *RealDebrid) Name() string { return "Real-Debrid" }
+
+func (rd *RealDebrid) Cached(ctx context.Context, hashes []string) (map[string]bool, error) {
+	// Unavailable hashes come back as an empty array rather than an object
+	var response map[string]json.RawMessage
+	path := "/torrents/instantAvailability/" + strings.Join(hashes, "/")
+	if err := rd.do(ctx, http.MethodGet, path, nil, &response); err != nil {
+		return nil, err
+	}
+
+	cached := map[string]bool{}
+	for hash, raw := range response {
+		var hosts struct {
+			RD []json.RawMessage `json:"rd"`
+		}
+		if json.Unmarshal(raw, &hosts) == nil && len(hosts.RD) > 0 {
+			cached[strings.ToLower(hash)] = true
+		}
+	}
+	return cached, nil
+}
+
+// realDebridFailed are the statuses of torrents that will never be ready
+var realDebridFailed = map[string]bool{"magnet_error": true, "error": true, "virus": true, "dead": true}
+
+// Find looks through the account's most recent torrents.
+func (rd *RealDebrid) Find(ctx context.Context, hash string) (string, error) {
+	var torrents []struct {
+		Id     string `json:"id"`
+		Hash   string `json:"hash"`
+		Status string `json:"status"`
+	}
+	if err := rd.do(ctx, http.MethodGet, "/torrents?limit=100", nil, &torrents); err != nil {
+		return "", err
+	}
+	for _, t := range torrents {
+		if strings.EqualFold(t.Hash, hash) && !realDebridFailed[t.Status] {
+			return t.Id, nil
+		}
+	}
+	return "", nil
+}
+
+func (rd *RealDebrid) AddMagnet(ctx context.Context, magnet string) (string, error) {
+	var response struct {
+		Id string `json:"id"`
+	}
+	if err := rd.do(ctx, http.MethodPost, "/torrents/addMagnet", url.Values{"magnet": {magnet}}, &response); err != nil {
+		return "", err
+	}
+	return response.Id, nil
+}
+
+// Link selects the file once the magnet is converted and unrestricts its
+// link when the torrent is downloaded. The torrent has a link per selected
+// file, in file order.
+func (rd *RealDebrid) Link(ctx context.Context, id string, fileIdx *int) (string, error) {
+	var info struct {
+		Status string `json:"status"`
+		Files  []struct {
+			Id       int   `json:"id"`
+			Bytes    int64 `json:"bytes"`
+			Selected int   `json:"selected"`
+		} `json:"files"`
+		Links []string `json:"links"`
+	}
+	if err := rd.do(ctx, http.MethodGet, "/torrents/info/"+url.PathEscape(id), nil, &info); err != nil {
+		return "", err
+	}
+	if info.Status == "magnet_conversion" || len(info.Files) == 0 {
+		return "", ErrNotReady
+	}
+	sizes := make([]int64, len(info.Files))
+	for i, f := range info.Files {
+		sizes[i] = f.Bytes
+	}
+	want := pickFile(sizes, fileIdx)
+
+	switch info.Status {
+	case "waiting_files_selection":
+		form := url.Values{"files": {strconv.Itoa(info.Files[want].Id)}}
+		if err := rd.do(ctx, http.MethodPost, "/torrents/selectFiles/"+url.PathEscape(id), form, nil); err != nil {
+			return "", err
+		}
+		return "", ErrNotReady
+	case "queued", "downloading", "compressing", "uploading":
+		return "", ErrNotReady
+	case "downloaded":
+	default:
+		return "", fmt.Errorf("torrent status %q", info.Status)
+	}
+
+	if info.Files[want].Selected != 1 {
+		return "", errOtherFile
+	}
+	link := 0
+	for _, f := range info.Files[:want] {
+		if f.Selected == 1 {
+			link++
+		}
+	}
+	if link >= len(info.Links) {
+		return "", errOtherFile
+	}
+
+	var unrestricted struct {
+		Download string `json:"download"`
+	}
+	if err := rd.do(ctx, http.MethodPost, "/unrestrict/link", url.Values{"link": {info.Links[link]}}, &unrestricted); err != nil {
+		return "", err
+	}
+	return unrestricted.Download, nil
+}
@@ -1,17 +1,28 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	config "github.com/rshero/stremio-tui/config"
 	"github.com/rshero/stremio-tui/tui"
 )
 
 func main() {
+	offline := flag.Bool("offline", false, "browse cached titles and downloads without network access")
+	cfgFlags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	if err := config.Load(cfgFlags); err != nil {
+		fmt.Fprintln(os.Stderr, "config:", err)
+		os.Exit(1)
+	}
+
 	p := tea.NewProgram(
-		tui.NewModel(),
+		tui.NewModel(*offline),
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
 	)
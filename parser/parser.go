@@ -0,0 +1,244 @@
+// Package parser pulls structured quality information out of the free text
+// addons put in stream names, descriptions and filenames.
+package parser
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// StreamInfo is what could be recognised about a stream. Fields are left
+// empty (or zero) when the text doesn't mention them.
+type StreamInfo struct {
+	Resolution string   // 2160p, 1080p, 720p, 480p
+	Height     int      // vertical resolution, for sorting
+	Source     string   // REMUX, BluRay, WEB-DL, WEBRip, HDTV, DVDRip, TS, CAM
+	VideoCodec string   // AV1, HEVC, AVC, VP9, XviD
+	HDR        []string // DV, HDR10+, HDR10, HDR
+	AudioCodec string   // Atmos, TrueHD, DTS-HD MA, DTS, DD+, DD, AAC, FLAC, Opus
+	Channels   string   // 7.1, 5.1, 2.0
+	Languages  []string // lower-case language names
+	Group      string   // release group
+	Size       int64    // bytes
+	Seeders    int
+	Cached     bool // debrid service has the file ready
+}
+
+// IsCam reports whether the source is a theatre recording.
+func (i StreamInfo) IsCam() bool {
+	return i.Source == "CAM" || i.Source == "TS"
+}
+
+type pattern struct {
+	re    *regexp.Regexp
+	value string
+}
+
+// Patterns are tried in order; the first match wins, so more specific ones
+// come first.
+var (
+	resolutionPatterns = []pattern{
+		{regexp.MustCompile(`(?i)\b(2160p|4k|uhd)\b`), "2160p"},
+		{regexp.MustCompile(`(?i)\b1440p\b`), "1440p"},
+		{regexp.MustCompile(`(?i)\b(1080p|fhd)\b`), "1080p"},
+		{regexp.MustCompile(`(?i)\b720p\b`), "720p"},
+		{regexp.MustCompile(`(?i)\b(576p|480p|sd)\b`), "480p"},
+	}
+	sourcePatterns = []pattern{
+		{regexp.MustCompile(`(?i)\bremux\b`), "REMUX"},
+		{regexp.MustCompile(`(?i)\b(blu-?ray|bdrip|brrip|bdremux)\b`), "BluRay"},
+		{regexp.MustCompile(`(?i)\bweb-?dl\b`), "WEB-DL"},
+		{regexp.MustCompile(`(?i)\bweb-?rip\b`), "WEBRip"},
+		{regexp.MustCompile(`(?i)\bweb\b`), "WEB-DL"},
+		{regexp.MustCompile(`(?i)\b(hdtv|pdtv)\b`), "HDTV"},
+		{regexp.MustCompile(`(?i)\b(dvdrip|dvd)\b`), "DVDRip"},
+		{regexp.MustCompile(`(?i)\b(telesync|hdts|ts)\b`), "TS"},
+		{regexp.MustCompile(`(?i)\b(cam|camrip|hdcam)\b`), "CAM"},
+	}
+	codecPatterns = []pattern{
+		{regexp.MustCompile(`(?i)\bav1\b`), "AV1"},
+		{regexp.MustCompile(`(?i)\b(x265|h\.?265|hevc)\b`), "HEVC"},
+		{regexp.MustCompile(`(?i)\b(x264|h\.?264|avc)\b`), "AVC"},
+		{regexp.MustCompile(`(?i)\bvp9\b`), "VP9"},
+		{regexp.MustCompile(`(?i)\b(xvid|divx)\b`), "XviD"},
+	}
+	hdrPatterns = []pattern{
+		{regexp.MustCompile(`(?i)\b(dv|dovi|dolby[ .]?vision)\b`), "DV"},
+		{regexp.MustCompile(`(?i)\bhdr10(\+|plus)`), "HDR10+"},
+		{regexp.MustCompile(`(?i)\bhdr10(?:$|[^+\w])`), "HDR10"},
+		{regexp.MustCompile(`(?i)\bhdr\b`), "HDR"},
+	}
+	audioPatterns = []pattern{
+		{regexp.MustCompile(`(?i)\batmos\b`), "Atmos"},
+		{regexp.MustCompile(`(?i)\btrue-?hd\b`), "TrueHD"},
+		{regexp.MustCompile(`(?i)\bdts-?hd([ .-]?ma)?\b`), "DTS-HD MA"},
+		{regexp.MustCompile(`(?i)\bdts(-?x)?\b`), "DTS"},
+		{regexp.MustCompile(`(?i)(\bddp|\bdd\+|\be-?ac-?3\b|\beac3\b)`), "DD+"},
+		{regexp.MustCompile(`(?i)(\bdd(\d|\b)|\bac-?3\b|\bdolby[ .]digital\b)`), "DD"},
+		{regexp.MustCompile(`(?i)\baac`), "AAC"},
+		{regexp.MustCompile(`(?i)\bflac`), "FLAC"},
+		{regexp.MustCompile(`(?i)\bopus\b`), "Opus"},
+	}
+
+	// The layout follows a codec ("DD5.1", "TrueHD.7.1", "AC3.5.1") or a
+	// separator, but not a number, so sizes like "10.5 GB" don't match
+	channelsRe = regexp.MustCompile(`(?i)(?:^|[^0-9.]|(?:[a-z]|ac-?3|eac-?3)\.)([1-9])[ .]([01])(?:ch)?\b`)
+	sizeRe     = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s?(TB|GB|MB|KB|GiB|MiB)\b`)
+	seedersRe  = regexp.MustCompile(`(?i)(?:👤|seeders?:?|seeds?:?|\bS:)\s*(\d+)`)
+	groupRe    = regexp.MustCompile(`-([A-Za-z0-9]+)(?:\[[^\]]*\])?$`)
+	cachedRe   = regexp.MustCompile(`(?i)(\[(RD|AD|PM|DL|TB|ED|OC|PP)\+\]|⚡|\binstant\b|\bcached\b)`)
+
+	// tagTails are the second halves of hyphenated tags, e.g. WEB-DL,
+	// Blu-Ray and DTS-HD, which groupRe would take for a group
+	tagTails = map[string]bool{"dl": true, "rip": true, "ray": true, "hd": true, "ma": true, "x": true, "3": true}
+
+	languages = map[string]string{
+		"english": "english", "eng": "english", "🇬🇧": "english", "🇺🇸": "english",
+		"french": "french", "fre": "french", "fra": "french", "vff": "french", "vostfr": "french", "🇫🇷": "french",
+		"german": "german", "ger": "german", "deu": "german", "🇩🇪": "german",
+		"spanish": "spanish", "spa": "spanish", "esp": "spanish", "castellano": "spanish", "latino": "spanish", "🇪🇸": "spanish", "🇲🇽": "spanish",
+		"italian": "italian", "ita": "italian", "🇮🇹": "italian",
+		"portuguese": "portuguese", "por": "portuguese", "🇵🇹": "portuguese", "🇧🇷": "portuguese",
+		"russian": "russian", "rus": "russian", "🇷🇺": "russian",
+		"hindi": "hindi", "hin": "hindi", "🇮🇳": "hindi",
+		"japanese": "japanese", "jpn": "japanese", "🇯🇵": "japanese",
+		"korean": "korean", "kor": "korean", "🇰🇷": "korean",
+		"chinese": "chinese", "chi": "chinese", "🇨🇳": "chinese",
+		"polish": "polish", "pol": "polish", "🇵🇱": "polish",
+		"dutch": "dutch", "🇳🇱": "dutch",
+		"turkish": "turkish", "tur": "turkish", "🇹🇷": "turkish",
+		"arabic": "arabic", "ara": "arabic", "🇸🇦": "arabic",
+		"multi": "multi",
+	}
+	// languageCodes are ISO 639-1 codes, accepted where languages are
+	// configured but too short to look for in release names
+	languageCodes = map[string]string{
+		"en": "english", "fr": "french", "de": "german", "es": "spanish", "it": "italian",
+		"pt": "portuguese", "ru": "russian", "hi": "hindi", "ja": "japanese", "ko": "korean",
+		"zh": "chinese", "pl": "polish", "nl": "dutch", "tr": "turkish", "ar": "arabic",
+	}
+	wordRe = regexp.MustCompile(`[\p{L}]+|[\x{1F1E6}-\x{1F1FF}]{2}`)
+)
+
+func match(patterns []pattern, text string) string {
+	for _, p := range patterns {
+		if p.re.MatchString(text) {
+			return p.value
+		}
+	}
+	return ""
+}
+
+// Parse extracts stream information from the stream's name, description
+// and filename. size is the exact size from the addon's behavior hints, or
+// zero to look for one in the text.
+func Parse(name, description, filename string, size int64) StreamInfo {
+	text := name + "\n" + description + "\n" + filename
+	info := StreamInfo{
+		Resolution: match(resolutionPatterns, text),
+		Source:     match(sourcePatterns, text),
+		VideoCodec: match(codecPatterns, text),
+		AudioCodec: match(audioPatterns, text),
+		Size:       size,
+		Cached:     cachedRe.MatchString(name + " " + description),
+	}
+	if info.Resolution != "" {
+		info.Height, _ = strconv.Atoi(strings.TrimSuffix(info.Resolution, "p"))
+	}
+
+	for _, p := range hdrPatterns {
+		if p.re.MatchString(text) {
+			info.HDR = append(info.HDR, p.value)
+		}
+	}
+
+	if m := channelsRe.FindStringSubmatch(text); m != nil {
+		info.Channels = m[1] + "." + m[2]
+	}
+
+	if info.Size == 0 {
+		info.Size = ParseSize(text)
+	}
+
+	if m := seedersRe.FindStringSubmatch(text); m != nil {
+		info.Seeders, _ = strconv.Atoi(m[1])
+	}
+
+	info.Languages = parseLanguages(text)
+	info.Group = parseGroup(filename, description)
+	return info
+}
+
+// ParseSize finds the first size like "4.2 GB" in text and returns it in
+// bytes, or zero if there is none.
+func ParseSize(text string) int64 {
+	m := sizeRe.FindStringSubmatch(text)
+	if m == nil {
+		return 0
+	}
+	n, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", "."), 64)
+	if err != nil {
+		return 0
+	}
+	switch strings.ToUpper(m[2]) {
+	case "TB":
+		n *= 1 << 40
+	case "GB", "GIB":
+		n *= 1 << 30
+	case "MB", "MIB":
+		n *= 1 << 20
+	case "KB":
+		n *= 1 << 10
+	}
+	return int64(n)
+}
+
+// Language returns the name Parse reports for a language written as a
+// name, code ("en", "eng") or flag, or "" if it isn't known.
+func Language(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if lang, ok := languages[s]; ok {
+		return lang
+	}
+	return languageCodes[s]
+}
+
+func parseLanguages(text string) []string {
+	seen := map[string]bool{}
+	var langs []string
+	for _, w := range wordRe.FindAllString(text, -1) {
+		lang, ok := languages[strings.ToLower(w)]
+		if ok && !seen[lang] {
+			seen[lang] = true
+			langs = append(langs, lang)
+		}
+	}
+	return langs
+}
+
+// parseGroup takes the release group from "Title.2024.1080p-GROUP.mkv",
+// falling back to the first line of the description, which many addons use
+// for the release name.
+func parseGroup(filename, description string) string {
+	release := filename
+	if release == "" {
+		release, _, _ = strings.Cut(description, "\n")
+	}
+	switch strings.ToLower(path.Ext(release)) {
+	case ".mkv", ".mp4", ".avi", ".m4v", ".webm":
+		release = strings.TrimSuffix(release, path.Ext(release))
+	}
+	m := groupRe.FindStringSubmatch(strings.TrimSpace(release))
+	if m == nil || tagTails[strings.ToLower(m[1])] {
+		return ""
+	}
+	// "Movie.1080p.BluRay-x264" has no group, only a codec after the dash
+	for _, patterns := range [][]pattern{resolutionPatterns, sourcePatterns, codecPatterns, hdrPatterns, audioPatterns} {
+		if match(patterns, m[1]) != "" {
+			return ""
+		}
+	}
+	return m[1]
+}
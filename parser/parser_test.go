@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAudio(t *testing.T) {
+	tests := []struct {
+		release  string
+		codec    string
+		channels string
+	}{
+		{"Dune.Part.Two.2024.2160p.UHD.BluRay.REMUX.DV.HDR.HEVC.TrueHD.7.1.Atmos-FGT.mkv", "Atmos", "7.1"},
+		{"Oppenheimer.2023.1080p.BluRay.DTS-HD.MA.5.1.x264-GRP.mkv", "DTS-HD MA", "5.1"},
+		{"The.Matrix.1999.720p.BRRip.AC3.5.1.x264-GRP.mkv", "DD", "5.1"},
+		{"Breaking.Bad.S01E01.1080p.WEB-DL.DD5.1.H.264-GRP.mkv", "DD", "5.1"},
+		{"The.Bear.S03E01.2160p.WEB-DL.DDP5.1.Atmos.HDR.H.265-FLUX.mkv", "Atmos", "5.1"},
+		{"Severance.S02E01.1080p.WEB.H264-GRP.mkv", "", ""},
+		{"Shogun.2024.S01E01.1080p.WEBRip.AAC2.0.x264-GRP.mkv", "AAC", "2.0"},
+		{"Movie.2020.1080p.WEB-DL.DD+2.0.H.264-GRP.mkv", "DD+", "2.0"},
+		{"Movie.2020.1080p.BluRay.x264 10.5 GB", "", ""},
+	}
+	for _, tt := range tests {
+		info := Parse("", "", tt.release, 0)
+		if info.AudioCodec != tt.codec {
+			t.Errorf("%s: audio codec = %q, want %q", tt.release, info.AudioCodec, tt.codec)
+		}
+		if info.Channels != tt.channels {
+			t.Errorf("%s: channels = %q, want %q", tt.release, info.Channels, tt.channels)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name, description, filename string
+		want                        StreamInfo
+	}{
+		{filename: "Dune.Part.Two.2024.2160p.UHD.BluRay.REMUX.DV.HDR10.HEVC.TrueHD.7.1.Atmos-FGT.mkv",
+			want: StreamInfo{Resolution: "2160p", Height: 2160, Source: "REMUX", VideoCodec: "HEVC",
+				HDR: []string{"DV", "HDR10"}, Group: "FGT"}},
+		{filename: "The.Bear.S03E01.2160p.WEB-DL.DDP5.1.HDR10+.H.265-FLUX.mkv",
+			want: StreamInfo{Resolution: "2160p", Height: 2160, Source: "WEB-DL", VideoCodec: "HEVC",
+				HDR: []string{"HDR10+"}, Group: "FLUX"}},
+		{filename: "Movie.2020.1080p.WEB-DL.mkv",
+			want: StreamInfo{Resolution: "1080p", Height: 1080, Source: "WEB-DL"}},
+		{filename: "Movie.2020.1080p.Blu-Ray.mkv",
+			want: StreamInfo{Resolution: "1080p", Height: 1080, Source: "BluRay"}},
+		{filename: "Movie.2020.720p.WEB-Rip.mp4",
+			want: StreamInfo{Resolution: "720p", Height: 720, Source: "WEBRip"}},
+		{filename: "Movie.2020.1080p.BluRay.DTS-HD",
+			want: StreamInfo{Resolution: "1080p", Height: 1080, Source: "BluRay"}},
+		{filename: "Movie.2020.1080p.BluRay-x264",
+			want: StreamInfo{Resolution: "1080p", Height: 1080, Source: "BluRay", VideoCodec: "AVC"}},
+		{filename: "Movie.2020.720p.HDTV.x264-GRP[eztv].mkv",
+			want: StreamInfo{Resolution: "720p", Height: 720, Source: "HDTV", VideoCodec: "AVC", Group: "GRP"}},
+		{filename: "Movie.2020.DVDRip.XviD-AAA.avi",
+			want: StreamInfo{Source: "DVDRip", VideoCodec: "XviD", Group: "AAA"}},
+		{filename: "Movie.2024.HDCAM.x264-NoGrp",
+			want: StreamInfo{Source: "CAM", VideoCodec: "AVC", Group: "NoGrp"}},
+		{filename: "Movie.2024.1080p.WEB.AV1-GRP.mkv",
+			want: StreamInfo{Resolution: "1080p", Height: 1080, Source: "WEB-DL", VideoCodec: "AV1", Group: "GRP"}},
+		{filename: "Movie.2020.MULTi.FRENCH.ENG.1080p.BluRay.x264-GRP.mkv",
+			want: StreamInfo{Resolution: "1080p", Height: 1080, Source: "BluRay", VideoCodec: "AVC",
+				Languages: []string{"multi", "french", "english"}, Group: "GRP"}},
+		{name: "Torrentio\n4k DV", description: "Movie.2023.2160p.WEB-DL.DV.HEVC-GRP\n👤 42 💾 12.5 GB ⚙️ ThePirateBay\n🇬🇧 / 🇩🇪",
+			want: StreamInfo{Resolution: "2160p", Height: 2160, Source: "WEB-DL", VideoCodec: "HEVC",
+				HDR: []string{"DV"}, Languages: []string{"english", "german"}, Group: "GRP",
+				Size: 12.5 * (1 << 30), Seeders: 42}},
+		{name: "[RD+] Torrentio 1080p", description: "Movie 1080p\nSeeders: 7 Size: 700 MB",
+			want: StreamInfo{Resolution: "1080p", Height: 1080, Size: 700 << 20, Seeders: 7, Cached: true}},
+		{name: "Comet ⚡", description: "Movie.720p.WEBRip",
+			want: StreamInfo{Resolution: "720p", Height: 720, Source: "WEBRip", Cached: true}},
+		{name: "Addon", description: "Movie.2020.1080p.BluRay.x264-GRP\n4,5 GB",
+			want: StreamInfo{Resolution: "1080p", Height: 1080, Source: "BluRay", VideoCodec: "AVC", Group: "GRP",
+				Size: 4.5 * (1 << 30)}},
+	}
+	for _, tt := range tests {
+		release := tt.filename
+		if release == "" {
+			release = tt.description
+		}
+		got := Parse(tt.name, tt.description, tt.filename, 0)
+		got.AudioCodec, got.Channels = "", "" // covered by TestParseAudio
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", release, got, tt.want)
+		}
+	}
+}
+
+func TestParseSizeHint(t *testing.T) {
+	// The exact size from the behavior hints wins over the text
+	if got := Parse("", "4.2 GB", "Movie.mkv", 123).Size; got != 123 {
+		t.Errorf("size = %d, want 123", got)
+	}
+}
@@ -0,0 +1,160 @@
+// Package player starts the external video player streams and downloaded
+// files are watched in.
+package player
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+
+	config "github.com/rshero/stremio-tui/config"
+	shell "github.com/rshero/stremio-tui/shell"
+)
+
+// Media is what to play.
+type Media struct {
+	Url       string
+	Title     string
+	Subtitles []string          // subtitle URLs, preferred first
+	Headers   map[string]string // request headers the stream needs
+}
+
+// Player is a video player.
+type Player interface {
+	Name() string
+
+	// Play starts the player without waiting for it to exit.
+	Play(m Media) error
+}
+
+// New creates the player described by cfg, checking that its executable
+// can be found.
+func New(cfg config.Player) (Player, error) {
+	var p Player
+	var binary string
+	switch cfg.Name {
+	case "mpv":
+		p, binary = &Mpv{binary: cfg.Binary(), args: cfg.Args}, cfg.Binary()
+	case "vlc":
+		p, binary = &Vlc{binary: cfg.Binary(), args: cfg.Args}, cfg.Binary()
+	case "command":
+		if strings.TrimSpace(cfg.Command) == "" {
+			return nil, fmt.Errorf("player command is empty")
+		}
+		// The template runs through sh, which finds the player itself
+		p, binary = &Command{template: cfg.Command}, "sh"
+	default:
+		return nil, fmt.Errorf("unknown player %q", cfg.Name)
+	}
+	if _, err := exec.LookPath(binary); err != nil {
+		return nil, fmt.Errorf("%s not found: %w", binary, err)
+	}
+	return p, nil
+}
+
+// Mpv plays through mpv, which also opens YouTube pages via yt-dlp.
+type Mpv struct {
+	binary string
+	args   []string // extra arguments from the config
+}
+
+func (p *Mpv) Name() string { return "mpv" }
+
+func (p *Mpv) Play(m Media) error {
+	return exec.Command(p.binary, p.arguments(m)...).Start()
+}
+
+// arguments lists the options, then the URL after "--" so a URL starting
+// with a dash isn't taken for an option.
+func (p *Mpv) arguments(m Media) []string {
+	args := append([]string{}, p.args...)
+	for _, u := range m.Subtitles {
+		args = append(args, "--sub-file="+u)
+	}
+	if m.Title != "" {
+		args = append(args, "--force-media-title="+m.Title)
+	}
+	// The append form of --http-header-fields keeps values containing commas
+	for _, k := range sortedKeys(m.Headers) {
+		args = append(args, "--http-header-fields-append="+k+": "+m.Headers[k])
+	}
+	return append(args, "--", m.Url)
+}
+
+// Vlc plays through VLC. Only the Referer and User-Agent headers can be
+// passed on.
+type Vlc struct {
+	binary string
+	args   []string
+}
+
+func (p *Vlc) Name() string { return "VLC" }
+
+func (p *Vlc) Play(m Media) error {
+	return exec.Command(p.binary, p.arguments(m)...).Start()
+}
+
+// arguments lists the options, then the URL after "--".
+func (p *Vlc) arguments(m Media) []string {
+	args := append([]string{}, p.args...)
+	if len(m.Subtitles) > 0 {
+		args = append(args, "--input-slave="+strings.Join(m.Subtitles, "#"))
+	}
+	if m.Title != "" {
+		args = append(args, "--meta-title="+m.Title)
+	}
+	for _, k := range sortedKeys(m.Headers) {
+		switch strings.ToLower(k) {
+		case "referer":
+			args = append(args, "--http-referrer="+m.Headers[k])
+		case "user-agent":
+			args = append(args, "--http-user-agent="+m.Headers[k])
+		}
+	}
+	return append(args, "--", m.Url)
+}
+
+// Command plays through a shell command, for other players and wrapper
+// scripts. {url}, {title} and {sub} (the first subtitle URL) in the
+// template are replaced with shell-quoted values, e.g.
+//
+//	celluloid --mpv-title={title} {url}
+type Command struct {
+	template string
+}
+
+// Name is the program the template runs, past any VAR=value assignments.
+func (p *Command) Name() string {
+	for _, f := range strings.Fields(p.template) {
+		if !assignmentRe.MatchString(f) {
+			return strings.Trim(f, `'"`)
+		}
+	}
+	return "command"
+}
+
+var assignmentRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+func (p *Command) Play(m Media) error {
+	var sub string
+	if len(m.Subtitles) > 0 {
+		sub = m.Subtitles[0]
+	}
+	line := strings.NewReplacer(
+		"{url}", shell.Quote(m.Url),
+		"{title}", shell.Quote(m.Title),
+		"{sub}", shell.Quote(sub),
+	).Replace(p.template)
+	return shell.Start(line)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
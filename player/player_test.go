@@ -0,0 +1,55 @@
+package player
+
+import (
+	"reflect"
+	"testing"
+
+	config "github.com/rshero/stremio-tui/config"
+)
+
+func TestArguments(t *testing.T) {
+	m := Media{
+		Url:       "-rf.mkv",
+		Title:     "Movie",
+		Subtitles: []string{"https://subs.example/en.srt"},
+		Headers:   map[string]string{"Referer": "https://site.example/"},
+	}
+	mpv := (&Mpv{args: []string{"--fs"}}).arguments(m)
+	wantMpv := []string{"--fs", "--sub-file=https://subs.example/en.srt", "--force-media-title=Movie",
+		"--http-header-fields-append=Referer: https://site.example/", "--", "-rf.mkv"}
+	if !reflect.DeepEqual(mpv, wantMpv) {
+		t.Errorf("mpv arguments = %q, want %q", mpv, wantMpv)
+	}
+	vlc := (&Vlc{}).arguments(m)
+	wantVlc := []string{"--input-slave=https://subs.example/en.srt", "--meta-title=Movie",
+		"--http-referrer=https://site.example/", "--", "-rf.mkv"}
+	if !reflect.DeepEqual(vlc, wantVlc) {
+		t.Errorf("vlc arguments = %q, want %q", vlc, wantVlc)
+	}
+}
+
+func TestCommandName(t *testing.T) {
+	tests := []struct{ template, want string }{
+		{"celluloid {url}", "celluloid"},
+		{"MPV_HOME=/tmp/mpv DISPLAY=:1 mpv {url}", "mpv"},
+		{"'/usr/local/bin/player' {url}", "/usr/local/bin/player"},
+		{"FOO=bar", "command"},
+	}
+	for _, tt := range tests {
+		if got := (&Command{template: tt.template}).Name(); got != tt.want {
+			t.Errorf("Name() for %q = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestNewCommandChecksShell(t *testing.T) {
+	// The template's own program is found by sh when it runs
+	for _, command := range []string{"MPV_HOME=/tmp/mpv mpv {url}", `"/opt/My Player/play" {url}`} {
+		if _, err := New(config.Player{Name: "command", Command: command}); err != nil {
+			t.Errorf("New(%q): %v", command, err)
+		}
+	}
+	if _, err := New(config.Player{Name: "command", Command: "  "}); err == nil {
+		t.Error("no error for an empty command")
+	}
+}
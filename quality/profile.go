@@ -0,0 +1,236 @@
+// Package quality scores streams against user-defined quality profiles so
+// the best match for a title can be picked without reading the whole list.
+package quality
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/rshero/stremio-tui/parser"
+)
+
+// Rule narrows one attribute of a stream. Forbidden values reject a stream,
+// a stream must have one of the Required values when any are set, and
+// Preferred values rank streams in list order, best first.
+type Rule struct {
+	Preferred []string `json:"preferred"`
+	Required  []string `json:"required"`
+	Forbidden []string `json:"forbidden"`
+}
+
+// match reports whether has accepts the rule, and how highly the stream ranks
+// among the preferred values (0 when it has none of them).
+func (r Rule) match(has func(string) bool) (rank int, ok bool) {
+	for _, v := range r.Forbidden {
+		if has(v) {
+			return 0, false
+		}
+	}
+	if len(r.Required) > 0 {
+		found := false
+		for _, v := range r.Required {
+			if has(v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	for i, v := range r.Preferred {
+		if has(v) {
+			return len(r.Preferred) - i, true
+		}
+	}
+	return 0, true
+}
+
+func (r Rule) values() []string {
+	return append(append(append([]string{}, r.Preferred...), r.Required...), r.Forbidden...)
+}
+
+// normalize rewrites each value with fn, so "4k" and "x265" in a profile
+// compare equal to the parser's "2160p" and "HEVC".
+func (r *Rule) normalize(fn func(string) string) {
+	for _, list := range [][]string{r.Preferred, r.Required, r.Forbidden} {
+		for i, v := range list {
+			if n := fn(v); n != "" {
+				list[i] = n
+			}
+		}
+	}
+}
+
+// Size is a byte count that can be written as a number or as "4 GB".
+type Size int64
+
+func (s *Size) UnmarshalJSON(data []byte) error {
+	var n int64
+	if err := json.Unmarshal(data, &n); err == nil {
+		*s = Size(n)
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	n = parser.ParseSize(text)
+	if n == 0 && strings.TrimSpace(text) != "" {
+		return fmt.Errorf("invalid size %q", text)
+	}
+	*s = Size(n)
+	return nil
+}
+
+// Profile is a named set of rules streams are scored against.
+type Profile struct {
+	Name        string `json:"name"`
+	Resolutions Rule   `json:"resolutions"`
+	Codecs      Rule   `json:"codecs"`
+	Languages   Rule   `json:"languages"`
+	Keywords    Rule   `json:"keywords"`
+	MinSize     Size   `json:"min_size"`
+	MaxSize     Size   `json:"max_size"`
+
+	keywords map[string]*regexp.Regexp
+}
+
+// Rank weights; resolution matters most, keywords least.
+const (
+	resolutionWeight = 100
+	codecWeight      = 10
+	languageWeight   = 10
+	keywordWeight    = 1
+)
+
+// Score rates a stream against the profile. text is the stream's name,
+// description and filename, searched for keywords. ok is false when the
+// stream breaks a required or forbidden rule or the size bounds; streams of
+// unknown size pass the bounds.
+func (p *Profile) Score(info parser.StreamInfo, text string) (score int, ok bool) {
+	if info.Size > 0 {
+		if p.MinSize > 0 && info.Size < int64(p.MinSize) {
+			return 0, false
+		}
+		if p.MaxSize > 0 && info.Size > int64(p.MaxSize) {
+			return 0, false
+		}
+	}
+
+	rules := []struct {
+		rule   Rule
+		weight int
+		has    func(string) bool
+	}{
+		{p.Resolutions, resolutionWeight, func(v string) bool {
+			return strings.EqualFold(info.Resolution, v)
+		}},
+		{p.Codecs, codecWeight, func(v string) bool {
+			return strings.EqualFold(info.VideoCodec, v)
+		}},
+		{p.Languages, languageWeight, func(v string) bool {
+			return containsFold(info.Languages, v)
+		}},
+		{p.Keywords, keywordWeight, func(v string) bool {
+			return p.keywords[v].MatchString(text) || strings.EqualFold(info.Source, v) ||
+				strings.EqualFold(info.AudioCodec, v) || containsFold(info.HDR, v)
+		}},
+	}
+	for _, r := range rules {
+		rank, ok := r.rule.match(r.has)
+		if !ok {
+			return 0, false
+		}
+		score += rank * r.weight
+	}
+	return score, true
+}
+
+// prepare normalizes the profile's values and compiles its keywords.
+// Languages the parser doesn't know are rejected, as no stream could match
+// them.
+func (p *Profile) prepare() error {
+	for _, v := range p.Languages.values() {
+		if parser.Language(v) == "" {
+			return fmt.Errorf("profile %q: unknown language %q", p.Name, v)
+		}
+	}
+	p.Resolutions.normalize(func(v string) string { return parser.Parse(v, "", "", 0).Resolution })
+	p.Codecs.normalize(func(v string) string { return parser.Parse(v, "", "", 0).VideoCodec })
+	p.Languages.normalize(parser.Language)
+
+	p.keywords = map[string]*regexp.Regexp{}
+	for _, k := range p.Keywords.values() {
+		p.keywords[k] = regexp.MustCompile(`(?i)(^|[^\pL\pN])` + regexp.QuoteMeta(k) + `($|[^\pL\pN])`)
+	}
+	return nil
+}
+
+func containsFold(list []string, v string) bool {
+	for _, s := range list {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultPath is where profiles are read from when QUALITY_PROFILES isn't
+// set: $XDG_CONFIG_HOME/stremio-tui/profiles.json.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "stremio-tui", "profiles.json"), nil
+}
+
+// Load reads the profiles file, a JSON object with a "profiles" list. A
+// missing file means no profiles.
+func Load(path string) ([]Profile, error) {
+	if path == "" {
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return nil, nil
+		}
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Profiles []Profile `json:"profiles"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i := range file.Profiles {
+		if file.Profiles[i].Name == "" {
+			return nil, fmt.Errorf("%s: profile %d has no name", path, i+1)
+		}
+		if err := file.Profiles[i].prepare(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return file.Profiles, nil
+}
+
+// Find returns the index of the named profile, or -1.
+func Find(profiles []Profile, name string) int {
+	for i, p := range profiles {
+		if strings.EqualFold(p.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,93 @@
+package quality
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rshero/stremio-tui/parser"
+)
+
+// loadProfile reads one profile from JSON the way Load does.
+func loadProfile(t *testing.T, profile string) *Profile {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	if err := os.WriteFile(path, []byte(`{"profiles": [`+profile+`]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	profiles, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &profiles[0]
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		release string
+		size    int64
+		score   int
+		ok      bool
+	}{
+		{"no rules", `{"name":"p"}`, "Movie.2020.1080p.WEB-DL.x264-GRP.mkv", 0, 0, true},
+		{"preferred resolution", `{"name":"p","resolutions":{"preferred":["1080p","720p"]}}`,
+			"Movie.2020.1080p.WEB-DL.x264-GRP.mkv", 0, 2 * resolutionWeight, true},
+		{"second preference", `{"name":"p","resolutions":{"preferred":["1080p","720p"]}}`,
+			"Movie.2020.720p.WEB-DL.x264-GRP.mkv", 0, resolutionWeight, true},
+		{"4k alias", `{"name":"p","resolutions":{"preferred":["4k"]}}`,
+			"Movie.2020.2160p.WEB-DL.x265-GRP.mkv", 0, resolutionWeight, true},
+		{"required resolution missing", `{"name":"p","resolutions":{"required":["2160p"]}}`,
+			"Movie.2020.1080p.WEB-DL.x264-GRP.mkv", 0, 0, false},
+		{"required resolution present", `{"name":"p","resolutions":{"required":["2160p","1080p"]}}`,
+			"Movie.2020.1080p.WEB-DL.x264-GRP.mkv", 0, 0, true},
+		{"forbidden resolution", `{"name":"p","resolutions":{"forbidden":["480p"]}}`,
+			"Movie.2020.480p.DVDRip.XviD-GRP.avi", 0, 0, false},
+		{"codec alias", `{"name":"p","codecs":{"preferred":["x265","x264"]}}`,
+			"Movie.2020.1080p.BluRay.HEVC-GRP.mkv", 0, 2 * codecWeight, true},
+		{"forbidden codec", `{"name":"p","codecs":{"forbidden":["h264"]}}`,
+			"Movie.2020.1080p.BluRay.x264-GRP.mkv", 0, 0, false},
+		{"language code", `{"name":"p","languages":{"required":["en"]}}`,
+			"Movie.2020.1080p.BluRay.ENG.x264-GRP.mkv", 0, 0, true},
+		{"language missing", `{"name":"p","languages":{"required":["fr"]}}`,
+			"Movie.2020.1080p.BluRay.ENG.x264-GRP.mkv", 0, 0, false},
+		{"forbidden keyword", `{"name":"p","keywords":{"forbidden":["CAM"]}}`,
+			"Movie.2020.720p.CAM.x264-GRP.mkv", 0, 0, false},
+		{"keyword inside a word", `{"name":"p","keywords":{"forbidden":["TS"]}}`,
+			"Movie.2020.1080p.WEB-DL.x264-TSUNAMI.mkv", 0, 0, true},
+		{"preferred source keyword", `{"name":"p","keywords":{"preferred":["BluRay","WEB-DL"]}}`,
+			"Movie.2020.1080p.WEB-DL.x264-GRP.mkv", 0, keywordWeight, true},
+		{"below min size", `{"name":"p","min_size":"1 GB"}`,
+			"Movie.2020.1080p.WEB-DL.x264-GRP.mkv", 500 << 20, 0, false},
+		{"above max size", `{"name":"p","max_size":"4 GB"}`,
+			"Movie.2020.1080p.WEB-DL.x264-GRP.mkv", 5 << 30, 0, false},
+		{"within size bounds", `{"name":"p","min_size":"1 GB","max_size":4294967296}`,
+			"Movie.2020.1080p.WEB-DL.x264-GRP.mkv", 2 << 30, 0, true},
+		{"unknown size passes", `{"name":"p","min_size":"1 GB","max_size":"4 GB"}`,
+			"Movie.2020.1080p.WEB-DL.x264-GRP.mkv", 0, 0, true},
+		{"weights add up", `{"name":"p","resolutions":{"preferred":["1080p"]},"codecs":{"preferred":["x264"]},"languages":{"preferred":["eng"]},"keywords":{"preferred":["WEB-DL"]}}`,
+			"Movie.2020.1080p.WEB-DL.ENG.x264-GRP.mkv", 0, resolutionWeight + codecWeight + languageWeight + keywordWeight, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := loadProfile(t, tt.profile)
+			score, ok := p.Score(parser.Parse("", "", tt.release, tt.size), tt.release)
+			if score != tt.score || ok != tt.ok {
+				t.Errorf("Score = %d, %v, want %d, %v", score, ok, tt.score, tt.ok)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsUnknownLanguage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	data := `{"profiles": [{"name": "p", "languages": {"preferred": ["klingon"]}}]}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), `"klingon"`) {
+		t.Errorf("err = %v, want unknown language", err)
+	}
+}
@@ -0,0 +1,23 @@
+// Package shell runs the user's command templates through sh.
+package shell
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// Quote wraps s in single quotes for sh.
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Start runs line with sh -c without waiting for it to finish; it is reaped
+// in the background once it exits. Only a failure to start is reported.
+func Start(line string) error {
+	cmd := exec.Command("sh", "-c", line)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
@@ -0,0 +1,55 @@
+// Package torrent hands magnet links to an external torrent client and
+// follows their progress where the client's API allows it.
+package torrent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	config "github.com/rshero/stremio-tui/config"
+)
+
+// Torrent is a torrent to hand off.
+type Torrent struct {
+	Magnet string // magnet URI, with trackers and display name
+	Hash   string // info hash
+	Name   string // display name
+}
+
+// Status is the progress of a handed-off torrent.
+type Status struct {
+	Progress float64 // 0 to 1
+	Done     bool
+	Error    string // set when the client reports the torrent as failed
+}
+
+// Client is an external torrent client.
+type Client interface {
+	Name() string
+
+	// Add hands the torrent to the client and returns the id its progress
+	// is polled with, or "" when the client can't report progress.
+	Add(ctx context.Context, t Torrent) (string, error)
+
+	// Progress reports the status of the torrents with the given ids.
+	// Torrents the client no longer knows are left out.
+	Progress(ctx context.Context, ids []string) (map[string]Status, error)
+}
+
+// New creates the client described by cfg, or returns nil when magnets
+// should go to the system handler.
+func New(cfg config.TorrentClient, timeout time.Duration) (Client, error) {
+	switch cfg.Client {
+	case "":
+		return nil, nil
+	case "qbittorrent":
+		return NewQBittorrent(cfg.Url, cfg.Username, cfg.Password, cfg.SavePath, timeout), nil
+	case "transmission":
+		return NewTransmission(cfg.Url, cfg.Username, cfg.Password, cfg.SavePath, timeout), nil
+	case "command":
+		return NewCommand(cfg.Command), nil
+	default:
+		return nil, fmt.Errorf("unknown torrent client %q", cfg.Client)
+	}
+}
@@ -0,0 +1,38 @@
+package torrent
+
+import (
+	"context"
+	"strings"
+
+	shell "github.com/rshero/stremio-tui/shell"
+)
+
+// Command hands torrents to a shell command. {magnet}, {hash} and {name} in
+// the template are replaced with the shell-quoted values, e.g.
+//
+//	aria2c --dir ~/Downloads {magnet}
+type Command struct {
+	template string
+}
+
+func NewCommand(template string) *Command {
+	return &Command{template: template}
+}
+
+func (c *Command) Name() string { return "command" }
+
+// Add starts the command without waiting for it, as downloaders like aria2c
+// run until the torrent is done. Only a failure to start is reported and
+// its progress can't be followed.
+func (c *Command) Add(ctx context.Context, t Torrent) (string, error) {
+	line := strings.NewReplacer(
+		"{magnet}", shell.Quote(t.Magnet),
+		"{hash}", shell.Quote(t.Hash),
+		"{name}", shell.Quote(t.Name),
+	).Replace(c.template)
+	return "", shell.Start(line)
+}
+
+func (c *Command) Progress(ctx context.Context, ids []string) (map[string]Status, error) {
+	return map[string]Status{}, nil
+}
@@ -0,0 +1,156 @@
+package torrent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	apiutils "github.com/rshero/stremio-tui/utils"
+)
+
+const qbittorrentUrl = "http://localhost:8080"
+
+// QBittorrent talks to the qBittorrent Web API (v2). Torrents are
+// identified by their info hash.
+type QBittorrent struct {
+	http               *http.Client
+	base               string
+	username, password string
+	savePath           string
+
+	mu       sync.Mutex
+	loggedIn bool
+}
+
+// NewQBittorrent creates a client for the Web UI at base (localhost:8080 when
+// empty). The login is skipped when username is empty, for Web UIs that
+// trust local connections.
+func NewQBittorrent(base, username, password, savePath string, timeout time.Duration) *QBittorrent {
+	if base == "" {
+		base = qbittorrentUrl
+	}
+	jar, _ := cookiejar.New(nil)
+	return &QBittorrent{
+		http:     &http.Client{Timeout: timeout, Jar: jar},
+		base:     strings.TrimSuffix(base, "/"),
+		username: username,
+		password: password,
+		savePath: savePath,
+		loggedIn: username == "",
+	}
+}
+
+func (q *QBittorrent) Name() string { return "qBittorrent" }
+
+func (q *QBittorrent) Add(ctx context.Context, t Torrent) (string, error) {
+	form := url.Values{"urls": {t.Magnet}}
+	if q.savePath != "" {
+		form.Set("savepath", q.savePath)
+	}
+	if _, err := q.call(ctx, http.MethodPost, "/api/v2/torrents/add", form); err != nil {
+		return "", err
+	}
+	return strings.ToLower(t.Hash), nil
+}
+
+func (q *QBittorrent) Progress(ctx context.Context, ids []string) (map[string]Status, error) {
+	query := url.Values{"hashes": {strings.Join(ids, "|")}}
+	body, err := q.call(ctx, http.MethodGet, "/api/v2/torrents/info?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var torrents []struct {
+		Hash     string  `json:"hash"`
+		Progress float64 `json:"progress"`
+		State    string  `json:"state"`
+	}
+	if err := json.Unmarshal(body, &torrents); err != nil {
+		return nil, &apiutils.DecodeError{Url: q.base, Err: err}
+	}
+
+	statuses := map[string]Status{}
+	for _, t := range torrents {
+		s := Status{Progress: t.Progress, Done: t.Progress >= 1}
+		if t.State == "error" || t.State == "missingFiles" {
+			s.Error = t.State
+		}
+		statuses[strings.ToLower(t.Hash)] = s
+	}
+	return statuses, nil
+}
+
+// call performs an API request, logging in first and again when the
+// session has expired.
+func (q *QBittorrent) call(ctx context.Context, method, path string, form url.Values) ([]byte, error) {
+	if err := q.login(ctx, false); err != nil {
+		return nil, err
+	}
+	body, err := q.request(ctx, method, path, form)
+	var httpErr *apiutils.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusForbidden && q.username != "" {
+		if err := q.login(ctx, true); err != nil {
+			return nil, err
+		}
+		return q.request(ctx, method, path, form)
+	}
+	return body, err
+}
+
+func (q *QBittorrent) login(ctx context.Context, again bool) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.loggedIn && !again {
+		return nil
+	}
+	body, err := q.request(ctx, http.MethodPost, "/api/v2/auth/login", url.Values{
+		"username": {q.username},
+		"password": {q.password},
+	})
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(body)) != "Ok." {
+		return fmt.Errorf("qBittorrent login failed")
+	}
+	q.loggedIn = true
+	return nil
+}
+
+func (q *QBittorrent) request(ctx context.Context, method, path string, form url.Values) ([]byte, error) {
+	apiUrl := q.base + path
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, apiUrl, body)
+	if err != nil {
+		return nil, err
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	// The Web UI rejects requests whose Referer doesn't match its host
+	req.Header.Set("Referer", q.base)
+
+	r, err := q.http.Do(req)
+	if err != nil {
+		return nil, &apiutils.NetworkError{Url: apiUrl, Err: err}
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, &apiutils.HTTPError{Url: apiUrl, StatusCode: r.StatusCode}
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, &apiutils.NetworkError{Url: apiUrl, Err: err}
+	}
+	return data, nil
+}
@@ -0,0 +1,181 @@
+package torrent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testHash = "0123456789ABCDEF0123456789ABCDEF01234567"
+
+// fakeQBittorrent serves the Web API, expiring the session once after
+// expireAfter successful calls.
+type fakeQBittorrent struct {
+	session     string
+	logins      int
+	calls       int
+	expireAfter int
+	added       []string
+}
+
+func (f *fakeQBittorrent) handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v2/auth/login", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("username") != "admin" || r.FormValue("password") != "secret" {
+			w.Write([]byte("Fails."))
+			return
+		}
+		f.logins++
+		f.session = fmt.Sprintf("sid%d", f.logins)
+		http.SetCookie(w, &http.Cookie{Name: "SID", Value: f.session, Path: "/"})
+		w.Write([]byte("Ok."))
+	})
+	authed := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Referer") == "" {
+				t.Errorf("%s without a Referer", r.URL.Path)
+			}
+			c, err := r.Cookie("SID")
+			if err != nil || c.Value != f.session {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			f.calls++
+			if f.calls == f.expireAfter {
+				f.session = ""
+			}
+			h(w, r)
+		}
+	}
+	mux.HandleFunc("POST /api/v2/torrents/add", authed(func(w http.ResponseWriter, r *http.Request) {
+		f.added = append(f.added, r.FormValue("urls"))
+		w.Write([]byte("Ok."))
+	}))
+	mux.HandleFunc("GET /api/v2/torrents/info", authed(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("hashes") != strings.ToLower(testHash) {
+			t.Errorf("hashes = %q", r.FormValue("hashes"))
+		}
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"hash": strings.ToLower(testHash), "progress": 0.25, "state": "downloading"},
+		})
+	}))
+	return mux
+}
+
+func TestQBittorrent(t *testing.T) {
+	fake := &fakeQBittorrent{expireAfter: 1}
+	srv := httptest.NewServer(fake.handler(t))
+	defer srv.Close()
+	q := NewQBittorrent(srv.URL, "admin", "secret", "", time.Second)
+	ctx := context.Background()
+
+	id, err := q.Add(ctx, Torrent{Magnet: "magnet:?xt=urn:btih:" + testHash, Hash: testHash})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != strings.ToLower(testHash) || len(fake.added) != 1 {
+		t.Errorf("id = %q, added %v", id, fake.added)
+	}
+
+	// The session expired after the add, so this logs in again
+	statuses, err := q.Progress(ctx, []string{id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fake.logins != 2 {
+		t.Errorf("logged in %d times, want 2", fake.logins)
+	}
+	if s := statuses[id]; s.Progress != 0.25 || s.Done || s.Error != "" {
+		t.Errorf("status = %+v", s)
+	}
+}
+
+func TestQBittorrentLoginFailed(t *testing.T) {
+	fake := &fakeQBittorrent{}
+	srv := httptest.NewServer(fake.handler(t))
+	defer srv.Close()
+
+	q := NewQBittorrent(srv.URL, "admin", "wrong", "", time.Second)
+	if _, err := q.Progress(context.Background(), []string{testHash}); err == nil {
+		t.Error("no error for a rejected login")
+	}
+}
+
+// fakeTransmission serves the RPC endpoint, handing out a new session id
+// with a 409 when the request's doesn't match.
+type fakeTransmission struct {
+	session   string
+	conflicts int
+}
+
+func (f *fakeTransmission) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Transmission-Session-Id") != f.session {
+		f.conflicts++
+		w.Header().Set("X-Transmission-Session-Id", f.session)
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+	var req struct {
+		Method    string         `json:"method"`
+		Arguments map[string]any `json:"arguments"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+	reply := func(args any) {
+		json.NewEncoder(w).Encode(map[string]any{"result": "success", "arguments": args})
+	}
+	switch req.Method {
+	case "torrent-add":
+		reply(map[string]any{"torrent-duplicate": map[string]any{"hashString": testHash}})
+	case "torrent-get":
+		reply(map[string]any{"torrents": []map[string]any{
+			{"hashString": testHash, "percentDone": 1.0},
+			{"hashString": "ffff", "percentDone": 0.5, "error": 3, "errorString": "No data found"},
+		}})
+	default:
+		json.NewEncoder(w).Encode(map[string]any{"result": "method not recognized"})
+	}
+}
+
+func TestTransmission(t *testing.T) {
+	fake := &fakeTransmission{session: "first"}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	tr := NewTransmission(srv.URL, "", "", "", time.Second)
+	ctx := context.Background()
+
+	id, err := tr.Add(ctx, Torrent{Magnet: "magnet:?xt=urn:btih:" + testHash, Hash: testHash})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != strings.ToLower(testHash) {
+		t.Errorf("id = %q", id)
+	}
+	if fake.conflicts != 1 {
+		t.Errorf("%d conflicts, want 1", fake.conflicts)
+	}
+
+	// The server restarted with a new session id
+	fake.session = "second"
+	statuses, err := tr.Progress(ctx, []string{id, "ffff"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fake.conflicts != 2 {
+		t.Errorf("%d conflicts, want 2", fake.conflicts)
+	}
+	if s := statuses[id]; !s.Done || s.Progress != 1 {
+		t.Errorf("status = %+v", s)
+	}
+	if s := statuses["ffff"]; s.Error != "No data found" {
+		t.Errorf("failed torrent status = %+v", s)
+	}
+
+	if err := tr.call(ctx, "session-close", nil, nil); err == nil {
+		t.Error("no error for a failed RPC result")
+	}
+}
@@ -0,0 +1,146 @@
+package torrent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	apiutils "github.com/rshero/stremio-tui/utils"
+)
+
+const transmissionUrl = "http://localhost:9091/transmission/rpc"
+
+// Transmission talks to the Transmission RPC interface. Torrents are
+// identified by their info hash.
+type Transmission struct {
+	http               *http.Client
+	url                string
+	username, password string
+	savePath           string
+
+	mu        sync.Mutex
+	sessionId string // CSRF token, handed out with a 409 response
+}
+
+// NewTransmission creates a client for the RPC endpoint at rpcUrl
+// (localhost:9091 when empty).
+func NewTransmission(rpcUrl, username, password, savePath string, timeout time.Duration) *Transmission {
+	if rpcUrl == "" {
+		rpcUrl = transmissionUrl
+	}
+	return &Transmission{
+		http:     &http.Client{Timeout: timeout},
+		url:      rpcUrl,
+		username: username,
+		password: password,
+		savePath: savePath,
+	}
+}
+
+func (t *Transmission) Name() string { return "Transmission" }
+
+func (t *Transmission) Add(ctx context.Context, tor Torrent) (string, error) {
+	args := map[string]any{"filename": tor.Magnet}
+	if t.savePath != "" {
+		args["download-dir"] = t.savePath
+	}
+	var result struct {
+		Added     *struct{ HashString string } `json:"torrent-added"`
+		Duplicate *struct{ HashString string } `json:"torrent-duplicate"`
+	}
+	if err := t.call(ctx, "torrent-add", args, &result); err != nil {
+		return "", err
+	}
+	switch {
+	case result.Added != nil:
+		return strings.ToLower(result.Added.HashString), nil
+	case result.Duplicate != nil:
+		return strings.ToLower(result.Duplicate.HashString), nil
+	}
+	return strings.ToLower(tor.Hash), nil
+}
+
+func (t *Transmission) Progress(ctx context.Context, ids []string) (map[string]Status, error) {
+	args := map[string]any{
+		"ids":    ids,
+		"fields": []string{"hashString", "percentDone", "error", "errorString"},
+	}
+	var result struct {
+		Torrents []struct {
+			HashString  string  `json:"hashString"`
+			PercentDone float64 `json:"percentDone"`
+			Error       int     `json:"error"`
+			ErrorString string  `json:"errorString"`
+		} `json:"torrents"`
+	}
+	if err := t.call(ctx, "torrent-get", args, &result); err != nil {
+		return nil, err
+	}
+
+	statuses := map[string]Status{}
+	for _, tor := range result.Torrents {
+		s := Status{Progress: tor.PercentDone, Done: tor.PercentDone >= 1}
+		if tor.Error != 0 {
+			s.Error = tor.ErrorString
+		}
+		statuses[strings.ToLower(tor.HashString)] = s
+	}
+	return statuses, nil
+}
+
+// call performs an RPC request, picking up a new session id and retrying
+// once when the server asks for one.
+func (t *Transmission) call(ctx context.Context, method string, args any, result any) error {
+	payload, err := json.Marshal(map[string]any{"method": method, "arguments": args})
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if t.username != "" {
+			req.SetBasicAuth(t.username, t.password)
+		}
+		t.mu.Lock()
+		req.Header.Set("X-Transmission-Session-Id", t.sessionId)
+		t.mu.Unlock()
+
+		r, err := t.http.Do(req)
+		if err != nil {
+			return &apiutils.NetworkError{Url: t.url, Err: err}
+		}
+		if r.StatusCode == http.StatusConflict {
+			r.Body.Close()
+			t.mu.Lock()
+			t.sessionId = r.Header.Get("X-Transmission-Session-Id")
+			t.mu.Unlock()
+			continue
+		}
+		defer r.Body.Close()
+		if r.StatusCode != http.StatusOK {
+			return &apiutils.HTTPError{Url: t.url, StatusCode: r.StatusCode}
+		}
+
+		var response struct {
+			Result    string          `json:"result"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&response); err != nil {
+			return &apiutils.DecodeError{Url: t.url, Err: err}
+		}
+		if response.Result != "success" {
+			return fmt.Errorf("transmission: %s", response.Result)
+		}
+		return json.Unmarshal(response.Arguments, result)
+	}
+	return &apiutils.HTTPError{Url: t.url, StatusCode: http.StatusConflict}
+}
@@ -0,0 +1,243 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	config "github.com/rshero/stremio-tui/config"
+	apiutils "github.com/rshero/stremio-tui/utils"
+)
+
+// loadAddons fetches the manifests of the configured addons for the addon
+// manager; refresh bypasses the cache.
+func (m Model) loadAddons(refresh bool) tea.Cmd {
+	addons := config.Addons()
+	urls := make([]string, len(addons))
+	for i, a := range addons {
+		urls[i] = a.Url
+	}
+	ctx := context.Background()
+	if refresh {
+		ctx = apiutils.WithRefresh(ctx)
+	}
+	return fetchAddonManifests(ctx, m.api, urls)
+}
+
+func (m Model) updateAddonsTab(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.addingAddon {
+		return m.updateAddonInput(msg)
+	}
+
+	addons := config.Addons()
+	switch msg.String() {
+	case "esc":
+		m.currentTab = MainTab
+		return m, nil
+	case "j", "down":
+		if m.addonIdx < len(addons)-1 {
+			m.addonIdx++
+		}
+		return m, nil
+	case "k", "up":
+		if m.addonIdx > 0 {
+			m.addonIdx--
+		}
+		return m, nil
+	case "a":
+		m.addingAddon = true
+		m.errorMsg = ""
+		m.statusMsg = ""
+		m.addonInput.SetValue("")
+		m.addonInput.Focus()
+		return m, textinput.Blink
+	case "ctrl+r":
+		m.addonManifests = nil
+		m.addonErrs = nil
+		return m, m.loadAddons(true)
+	}
+
+	if m.addonIdx >= len(addons) {
+		return m, nil
+	}
+	i := m.addonIdx
+	switch msg.String() {
+	case " ":
+		addons[i].Disabled = !addons[i].Disabled
+	case "x", "delete":
+		addons = append(addons[:i], addons[i+1:]...)
+		if m.addonIdx > 0 && m.addonIdx >= len(addons) {
+			m.addonIdx--
+		}
+	case "K", "shift+up":
+		if i == 0 {
+			return m, nil
+		}
+		addons[i-1], addons[i] = addons[i], addons[i-1]
+		m.addonIdx--
+	case "J", "shift+down":
+		if i == len(addons)-1 {
+			return m, nil
+		}
+		addons[i], addons[i+1] = addons[i+1], addons[i]
+		m.addonIdx++
+	default:
+		return m, nil
+	}
+	m.saveAddons(addons)
+	return m, nil
+}
+
+// updateAddonInput handles keys while typing the URL of an addon to add
+func (m Model) updateAddonInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.addingAddon = false
+		m.checkingAddon = false
+		m.addonInput.Blur()
+		return m, nil
+	case "enter":
+		if m.checkingAddon {
+			return m, nil
+		}
+		u := strings.TrimSpace(m.addonInput.Value())
+		if err := config.ValidateAddonUrl(u); err != nil {
+			m.errorMsg = "Invalid addon URL: " + err.Error()
+			return m, nil
+		}
+		for _, a := range config.Addons() {
+			if apiutils.NormalizeAddonUrl(a.Url) == apiutils.NormalizeAddonUrl(u) {
+				m.errorMsg = "That addon is already installed"
+				return m, nil
+			}
+		}
+		m.checkingAddon = true
+		m.errorMsg = ""
+		m.statusMsg = "Checking manifest..."
+		return m, checkAddon(context.Background(), m.api, u)
+	}
+
+	var cmd tea.Cmd
+	m.addonInput, cmd = m.addonInput.Update(msg)
+	return m, cmd
+}
+
+// addonChecked installs the addon once its manifest has been validated
+func (m Model) addonChecked(msg addonCheckedMsg) (tea.Model, tea.Cmd) {
+	if !m.checkingAddon {
+		return m, nil
+	}
+	m.checkingAddon = false
+	m.statusMsg = ""
+	if msg.err != nil {
+		m.errorMsg = "Couldn't add addon: " + msg.err.Error()
+		return m, nil
+	}
+
+	m.addingAddon = false
+	m.addonInput.Blur()
+	if m.addonManifests == nil {
+		m.addonManifests = map[string]*apiutils.Addon{}
+	}
+	m.addonManifests[msg.url] = msg.addon
+
+	addons := append(config.Addons(), config.Addon{Url: msg.url})
+	m.addonIdx = len(addons) - 1
+	m.saveAddons(addons)
+	if m.errorMsg == "" {
+		m.statusMsg = "Added " + msg.addon.Name()
+	}
+	return m, nil
+}
+
+// saveAddons puts the new addon list in use and writes it to the config file
+func (m *Model) saveAddons(addons []config.Addon) {
+	if err := config.SaveAddons(addons); err != nil {
+		m.errorMsg = "Couldn't save addons: " + err.Error()
+		return
+	}
+	m.errorMsg = ""
+}
+
+// addonCapabilities summarises what an addon serves, e.g.
+// "stream, meta • movie, series • 2 catalogs"
+func addonCapabilities(a *apiutils.Addon) string {
+	parts := []string{strings.Join(a.Manifest.ResourceNames(), ", ")}
+	if len(a.Manifest.Types) > 0 {
+		parts = append(parts, strings.Join(a.Manifest.Types, ", "))
+	}
+	if n := len(a.Manifest.Catalogs); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d catalogs", n))
+	}
+	return strings.Join(parts, " • ")
+}
+
+func (m Model) addonsPageView() string {
+	var b strings.Builder
+
+	b.WriteString(TitleStyle.Render("Addons") + "\n\n")
+
+	addons := config.Addons()
+	if config.AddonsOverridden() {
+		b.WriteString(DimStyle.Render("Set by ALC_ADDON_URL or --addons: changes last until you quit.") + "\n\n")
+	}
+	if len(addons) == 0 {
+		b.WriteString(DimStyle.Render("No addons installed. Press 'a' to add one by its manifest URL.") + "\n")
+	}
+	for i, a := range addons {
+		selector := "  "
+		nameStyle := NormalStyle
+		if i == m.addonIdx {
+			selector = "› "
+			nameStyle = SelectedStyle
+		}
+		state := SuccessStyle.Render("✓")
+		if a.Disabled {
+			state = DimStyle.Render("○")
+			nameStyle = DimStyle
+		}
+
+		name, details := apiutils.NormalizeAddonUrl(a.Url), DimStyle.Render("loading manifest...")
+		if addon, ok := m.addonManifests[a.Url]; ok {
+			name = addon.Name()
+			if addon.Manifest.Version != "" {
+				name += " v" + addon.Manifest.Version
+			}
+			details = DimStyle.Render(addonCapabilities(addon))
+		} else if err, ok := m.addonErrs[a.Url]; ok {
+			details = ErrorStyle.Render(err.Error())
+		}
+		if a.Disabled {
+			details += DimStyle.Render(" • disabled")
+		}
+
+		b.WriteString(fmt.Sprintf("%s%s %s\n", selector, state, nameStyle.Render(name)))
+		b.WriteString("     " + details + "\n")
+		b.WriteString("     " + DimStyle.Render(a.Url) + "\n\n")
+	}
+
+	if m.addingAddon {
+		b.WriteString(InputStyle.Render(m.addonInput.View()) + "\n\n")
+	}
+	if m.errorMsg != "" {
+		b.WriteString(ErrorStyle.Render(m.errorMsg) + "\n\n")
+	} else if m.statusMsg != "" {
+		b.WriteString(SuccessStyle.Render(m.statusMsg) + "\n\n")
+	}
+
+	if m.addingAddon {
+		b.WriteString(HelpStyle.Render("enter: check and add • esc: cancel"))
+	} else {
+		b.WriteString(HelpStyle.Render("j/k: navigate • a: add • space: enable/disable • x: remove • K/J: move up/down • " +
+			keyFor(config.ScopeAddons, "refresh") + ": reload • esc/" + keyFor(config.ScopeAddons, "quit") + ": back to main"))
+	}
+
+	return lipgloss.NewStyle().
+		Width(m.width).
+		Height(m.height - 4). // Reserve space for tab bar
+		Render(b.String())
+}
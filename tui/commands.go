@@ -1,14 +1,24 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/cavaliergopher/grab/v3"
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/rshero/stremio-tui/debrid"
+	"github.com/rshero/stremio-tui/player"
+	"github.com/rshero/stremio-tui/torrent"
 	apiutils "github.com/rshero/stremio-tui/utils"
 )
 
@@ -21,19 +31,70 @@ func SetProgramRef(p *tea.Program) {
 
 // Message types
 type searchResultsMsg struct {
+	query   string
 	results []apiutils.ImdbSearchResult
+	next    apiutils.SearchCursor
+	more    bool // another page can be loaded
+	appends bool // a later page of the current results
+	stale   bool // served from an expired cache entry
+}
+
+// suggestTickMsg fires once typing has paused; seq identifies the edit
+type suggestTickMsg struct {
+	seq   int
+	query string
+}
+
+type suggestionsMsg struct {
+	seq     int
+	results []apiutils.ImdbSearchResult
+}
+
+// titleDetailsMsg carries the full record of the title in the detail view.
+// On error result is what was already known.
+type titleDetailsMsg struct {
+	result apiutils.ImdbSearchResult
+	err    error
+}
+
+// titleLinkMsg is a pasted link resolved to its title and, for series, the
+// seasons and the episodes of the linked season.
+type titleLinkMsg struct {
+	link     apiutils.TitleLink
+	title    apiutils.ImdbSearchResult
+	seasons  []apiutils.Season
+	episodes []apiutils.Episode
+}
+
+type subtitlesResultsMsg struct {
+	id      string
+	results []apiutils.Subtitle
 }
 
 type streamsResultsMsg struct {
-	results []apiutils.AlcSearchResult
+	results  []apiutils.AlcSearchResult
+	failures []apiutils.AddonError
+	stale    bool
+}
+
+type catalogsResultsMsg struct {
+	catalogs []apiutils.CatalogRef
+	failures []apiutils.AddonError
+}
+
+type catalogItemsMsg struct {
+	metas []apiutils.MetaPreview
+	skip  int
 }
 
 type seasonsResultsMsg struct {
 	results []apiutils.Season
+	stale   bool
 }
 
 type episodesResultsMsg struct {
 	results []apiutils.Episode
+	stale   bool
 }
 
 type downloadProgressMsg struct {
@@ -57,60 +118,449 @@ type batchStreamResultMsg struct {
 	err     error
 }
 
-type mpvLaunchedMsg struct {
-	err error
+// playerLaunchedMsg reports starting the video player
+type playerLaunchedMsg struct {
+	player    string
+	subtitles int
+	err       error
+}
+
+// addonManifestsMsg carries the manifests of the configured addons, keyed by
+// addon URL, and the errors of those that failed to load
+type addonManifestsMsg struct {
+	addons map[string]*apiutils.Addon
+	errs   map[string]error
+}
+
+// addonCheckedMsg reports validating the manifest of an addon to install
+type addonCheckedMsg struct {
+	url   string
+	addon *apiutils.Addon
+	err   error
+}
+
+// torrentAddedMsg reports handing download id's torrent to the torrent
+// client; torrentId is empty when its progress can't be followed
+type torrentAddedMsg struct {
+	id        int
+	torrentId string
+	err       error
+}
+
+// torrentPollMsg is the tick to poll the torrent client for progress
+type torrentPollMsg struct{}
+
+// torrentProgressMsg carries the status of handed-off torrents by torrent id
+type torrentProgressMsg struct {
+	statuses map[string]torrent.Status
+	err      error
+}
+
+// openedMsg reports handing a link to the system handler (browser, torrent
+// client)
+type openedMsg struct {
+	status string
+	err    error
 }
 
 type errorMsg string
 
+// requestError words a failed request so the user can tell a network problem
+// from a server or response problem. what completes "Couldn't ...".
+func requestError(what string, err error) errorMsg {
+	var netErr *apiutils.NetworkError
+	var httpErr *apiutils.HTTPError
+	var decodeErr *apiutils.DecodeError
+	switch {
+	case errors.As(err, &netErr):
+		return errorMsg(fmt.Sprintf("Couldn't %s: network unavailable (%v)", what, netErr.Err))
+	case errors.As(err, &httpErr):
+		return errorMsg(fmt.Sprintf("Couldn't %s: server returned %d %s", what, httpErr.StatusCode, http.StatusText(httpErr.StatusCode)))
+	case errors.As(err, &decodeErr):
+		return errorMsg(fmt.Sprintf("Couldn't %s: unexpected response from server", what))
+	default:
+		return errorMsg(fmt.Sprintf("Couldn't %s: %v", what, err))
+	}
+}
+
 // Commands
-func searchIMDB(query string) tea.Cmd {
+//
+// Every command takes the context of the view that started it. When the user
+// leaves that view the context is cancelled and the command returns nil
+// instead of a message, so abandoned requests never reach Update.
+
+// searchPageSize is how many search results are loaded at a time, and
+// maxEmptyPages how many pages in a row the filters may empty before paging
+// stops
+const (
+	searchPageSize = 20
+	maxEmptyPages  = 5
+)
+
+// searchIMDB loads the page of results at cursor; the zero cursor starts a
+// new search.
+func searchIMDB(ctx context.Context, api *apiutils.Client, query string, filter apiutils.SearchFilter, cursor apiutils.SearchCursor) tea.Cmd {
 	return func() tea.Msg {
-		results := apiutils.ImdbSearch(query, 20)
-		return searchResultsMsg{results: results}
+		ctx, report := apiutils.WithCacheReport(ctx)
+		page, err := api.ImdbSearchPage(ctx, query, searchPageSize, filter, cursor)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return requestError("search", err)
+		}
+		stale, _ := report.Stale()
+		return searchResultsMsg{
+			query:   query,
+			results: page.Titles,
+			next:    page.Next,
+			more:    page.More,
+			appends: cursor != apiutils.SearchCursor{},
+			stale:   stale,
+		}
+	}
+}
+
+// Search-as-you-type waits for suggestDelay of idle typing and shows up to
+// suggestLimit titles once the query is suggestMinLen characters long.
+const (
+	suggestDelay  = 300 * time.Millisecond
+	suggestLimit  = 8
+	suggestMinLen = 2
+)
+
+// debounceSuggest waits for typing to pause before asking for suggestions.
+func debounceSuggest(seq int, query string) tea.Cmd {
+	return tea.Tick(suggestDelay, func(time.Time) tea.Msg {
+		return suggestTickMsg{seq: seq, query: query}
+	})
+}
+
+// fetchSuggestions looks up titles for the dropdown. Failures are dropped;
+// suggestions are only a shortcut and Enter still runs a full search.
+func fetchSuggestions(ctx context.Context, api *apiutils.Client, seq int, query string) tea.Cmd {
+	return func() tea.Msg {
+		results, err := api.ImdbSearch(ctx, query, suggestLimit)
+		if ctx.Err() != nil || err != nil {
+			return nil
+		}
+		return suggestionsMsg{seq: seq, results: results}
+	}
+}
+
+func fetchTitleDetails(ctx context.Context, api *apiutils.Client, result apiutils.ImdbSearchResult) tea.Cmd {
+	return func() tea.Msg {
+		details, err := api.TitleDetails(ctx, result)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return titleDetailsMsg{result: details, err: err}
+	}
+}
+
+// resolveTitleLink loads what's needed to jump straight to the linked title,
+// season or episode.
+func resolveTitleLink(ctx context.Context, api *apiutils.Client, link apiutils.TitleLink) tea.Cmd {
+	return func() tea.Msg {
+		title := apiutils.ImdbSearchResult{Id: link.Id, PrimaryTitle: link.Id}
+		switch link.Type {
+		case "series":
+			title.Type = "tvSeries"
+		case "movie":
+			title.Type = "movie"
+		case "":
+		default:
+			title.Type, title.AddonType = link.Type, link.Type
+		}
+		title, err := api.TitleDetails(ctx, title)
+		if ctx.Err() != nil {
+			return nil
+		}
+		// Without details there is no telling a movie from a series
+		if err != nil && title.Type == "" {
+			return requestError("look up "+link.Id, err)
+		}
+		if title.Type == "tvEpisode" {
+			return errorMsg(link.Id + " is a single episode - open its series instead")
+		}
+
+		msg := titleLinkMsg{link: link, title: title}
+		if title.StremioType() != "series" {
+			return msg
+		}
+		if msg.seasons, err = api.LoadSeasons(ctx, title.Id); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return requestError("load seasons", err)
+		}
+		if link.Season > 0 {
+			if msg.episodes, err = api.LoadEpisodes(ctx, title.Id, strconv.Itoa(link.Season)); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return requestError("load episodes", err)
+			}
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		return msg
+	}
+}
+
+// fetchAddonManifests loads the manifests of every configured addon, enabled
+// or not, at once.
+func fetchAddonManifests(ctx context.Context, api *apiutils.Client, urls []string) tea.Cmd {
+	return func() tea.Msg {
+		msg := addonManifestsMsg{addons: map[string]*apiutils.Addon{}, errs: map[string]error{}}
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, u := range urls {
+			wg.Add(1)
+			go func(u string) {
+				defer wg.Done()
+				addon, err := api.LoadAddon(ctx, u)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					msg.errs[u] = err
+					return
+				}
+				msg.addons[u] = addon
+			}(u)
+		}
+		wg.Wait()
+		return msg
 	}
 }
 
-func fetchStreams(id string) tea.Cmd {
+// checkAddon validates the manifest of an addon before it is installed.
+func checkAddon(ctx context.Context, api *apiutils.Client, addonUrl string) tea.Cmd {
 	return func() tea.Msg {
-		results, err := apiutils.AlcStream(id)
+		addon, err := api.CheckAddon(ctx, addonUrl)
+		return addonCheckedMsg{url: addonUrl, addon: addon, err: err}
+	}
+}
+
+// playTrailer looks up the title's trailer and plays it like a YouTube
+// stream.
+func playTrailer(ctx context.Context, api *apiutils.Client, vp player.Player, result apiutils.ImdbSearchResult) tea.Cmd {
+	return func() tea.Msg {
+		ytId, err := api.Trailer(ctx, result)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return requestError("find a trailer", err)
+		}
+		trailer := apiutils.AlcSearchResult{Name: "Trailer", YtId: ytId}
+		title := result.PrimaryTitle + " (trailer)"
+		return playStream(ctx, api, nil, vp, result.StremioType(), result.Id, title, trailer, nil, nil)()
+	}
+}
+
+func fetchStreams(ctx context.Context, api *apiutils.Client, contentType, id string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, report := apiutils.WithCacheReport(ctx)
+		results, failures := api.AggregateStreams(ctx, contentType, id)
+		if ctx.Err() != nil {
+			return nil
+		}
+		stale, _ := report.Stale()
+		return streamsResultsMsg{results: results, failures: failures, stale: stale}
+	}
+}
+
+// fetchSubtitles loads the title's subtitle tracks without file hints, so the
+// language picker can be filled while the streams are being shown.
+func fetchSubtitles(ctx context.Context, api *apiutils.Client, contentType, id string) tea.Cmd {
+	return func() tea.Msg {
+		results, _ := api.AggregateSubtitles(ctx, contentType, id, apiutils.BehaviorHints{})
+		if ctx.Err() != nil {
+			return nil
+		}
+		return subtitlesResultsMsg{id: id, results: results}
+	}
+}
+
+func fetchCatalogs(ctx context.Context, api *apiutils.Client) tea.Cmd {
+	return func() tea.Msg {
+		catalogs, failures := api.Catalogs(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return catalogsResultsMsg{catalogs: catalogs, failures: failures}
+	}
+}
+
+func fetchCatalogItems(ctx context.Context, ref apiutils.CatalogRef, skip int, genre string) tea.Cmd {
+	return func() tea.Msg {
+		metas, err := ref.Addon.Catalog(ctx, ref.Catalog.Type, ref.Catalog.Id, skip, genre)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return requestError("load catalog", err)
+		}
+		return catalogItemsMsg{metas: metas, skip: skip}
+	}
+}
+
+func fetchSeasons(ctx context.Context, api *apiutils.Client, id string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, report := apiutils.WithCacheReport(ctx)
+		results, err := api.LoadSeasons(ctx, id)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return requestError("fetch seasons", err)
+		}
+		stale, _ := report.Stale()
+		return seasonsResultsMsg{results: results, stale: stale}
+	}
+}
+
+func fetchEpisodes(ctx context.Context, api *apiutils.Client, id string, season string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, report := apiutils.WithCacheReport(ctx)
+		results, err := api.LoadEpisodes(ctx, id, season)
+		if ctx.Err() != nil {
+			return nil
+		}
 		if err != nil {
-			return errorMsg(fmt.Sprintf("Failed to fetch streams: %v", err))
+			return requestError("fetch episodes", err)
+		}
+		stale, _ := report.Stale()
+		return episodesResultsMsg{results: results, stale: stale}
+	}
+}
+
+// playStream launches the player with one subtitle track per chosen
+// language. When the stream carries a video hash the subtitles are asked for
+// again with the file hints, so addons can return tracks matched to that
+// exact release.
+func playStream(ctx context.Context, api *apiutils.Client, svc debrid.Service, vp player.Player, contentType, id, title string, stream apiutils.AlcSearchResult, langs []string, subtitles []apiutils.Subtitle) tea.Cmd {
+	return func() tea.Msg {
+		switch stream.Kind() {
+		case apiutils.StreamExternal:
+			return openedMsg{status: "Opened in browser", err: openExternal(stream.ExternalUrl)}
+		case apiutils.StreamTorrent:
+			if svc == nil {
+				return openedMsg{status: "Sent magnet to torrent client", err: openExternal(stream.MagnetURI())}
+			}
+			var err error
+			stream, err = resolveTorrent(ctx, svc, stream)
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != nil {
+				return requestError("resolve the torrent", err)
+			}
+		case apiutils.StreamUnknown:
+			return errorMsg("This stream has no playable source")
+		}
+
+		// Tracks bundled with the stream come first, then addon tracks
+		// matched to the file's hash, then the rest of the addon ones
+		var matched []apiutils.Subtitle
+		if len(langs) > 0 && stream.BehaviorHints.VideoHash != "" {
+			matched, _ = api.AggregateSubtitles(ctx, contentType, id, stream.BehaviorHints)
+		}
+		subtitles = apiutils.MergeSubtitles(stream.Subtitles, matched, subtitles)
+		subUrls := apiutils.PickSubtitles(subtitles, langs)
+
+		err := vp.Play(player.Media{
+			Url:       stream.PlayableUrl(),
+			Title:     title,
+			Subtitles: subUrls,
+			Headers:   stream.BehaviorHints.ProxyHeaders.Request,
+		})
+		return playerLaunchedMsg{player: vp.Name(), subtitles: len(subUrls), err: err}
+	}
+}
+
+// debridTimeout bounds waiting for the debrid service to hand out a link
+const debridTimeout = time.Minute
+
+// resolveTorrent gives a torrent stream the direct URL the debrid service
+// serves its file from.
+func resolveTorrent(ctx context.Context, svc debrid.Service, stream apiutils.AlcSearchResult) (apiutils.AlcSearchResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, debridTimeout)
+	defer cancel()
+	link, err := debrid.Resolve(ctx, svc, stream.InfoHash, stream.MagnetURI(), stream.FileIdx)
+	if err != nil {
+		return stream, err
+	}
+	stream.Url = link
+	return stream, nil
+}
+
+// torrentPollInterval is how often handed-off torrents are polled
+const torrentPollInterval = 3 * time.Second
+
+// handOffTorrent sends a torrent stream's magnet to the torrent client.
+func handOffTorrent(client torrent.Client, id int, stream apiutils.AlcSearchResult) tea.Cmd {
+	return func() tea.Msg {
+		name := stream.BehaviorHints.Filename
+		if name == "" {
+			name = stream.Name
 		}
-		return streamsResultsMsg{results: results}
+		t := torrent.Torrent{Magnet: stream.MagnetURI(), Hash: stream.InfoHash, Name: name}
+		torrentId, err := client.Add(context.Background(), t)
+		return torrentAddedMsg{id: id, torrentId: torrentId, err: err}
 	}
 }
 
-func fetchSeasons(id string) tea.Cmd {
+func pollTorrents() tea.Cmd {
+	return tea.Tick(torrentPollInterval, func(time.Time) tea.Msg {
+		return torrentPollMsg{}
+	})
+}
+
+func fetchTorrentProgress(client torrent.Client, ids []string) tea.Cmd {
 	return func() tea.Msg {
-		results := apiutils.FetchSeasons(id)
-		return seasonsResultsMsg{results: results}
+		statuses, err := client.Progress(context.Background(), ids)
+		return torrentProgressMsg{statuses: statuses, err: err}
 	}
 }
 
-func fetchEpisodes(id string, season string) tea.Cmd {
+// openLink hands a link to the system handler
+func openLink(link, status string) tea.Cmd {
 	return func() tea.Msg {
-		results := apiutils.FetchEpisodes(id, season)
-		return episodesResultsMsg{results: results}
+		return openedMsg{status: status, err: openExternal(link)}
 	}
 }
 
-func playStream(url string) tea.Cmd {
+// openExternal opens link with the platform's default handler
+func openExternal(link string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", link)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", link)
+	default:
+		cmd = exec.Command("xdg-open", link)
+	}
+	return cmd.Start()
+}
+
+// playFile plays an already downloaded file, which works offline.
+func playFile(vp player.Player, path string) tea.Cmd {
 	return func() tea.Msg {
-		cmd := exec.Command("mpv", url)
-		err := cmd.Start()
-		return mpvLaunchedMsg{err: err}
+		err := vp.Play(player.Media{Url: path, Title: filepath.Base(path)})
+		return playerLaunchedMsg{player: vp.Name(), err: err}
 	}
 }
 
-func fetchBatchStreams(titleId string, season string, episode apiutils.Episode, delay time.Duration) tea.Cmd {
+func fetchBatchStreams(ctx context.Context, api *apiutils.Client, titleId string, episode apiutils.Episode) tea.Cmd {
 	return func() tea.Msg {
-		// Stagger requests to avoid rate limiting
-		if delay > 0 {
-			time.Sleep(delay)
+		results, err := api.AlcStream(ctx, "series", episode.StreamId(titleId))
+		if ctx.Err() != nil {
+			return nil
 		}
-		streamId := fmt.Sprintf("%s:%s:%d", titleId, season, episode.EpisodeNumber)
-		results, err := apiutils.AlcStream(streamId)
 		return batchStreamResultMsg{episode: episode, streams: results, err: err}
 	}
 }
@@ -118,7 +568,7 @@ func fetchBatchStreams(titleId string, season string, episode apiutils.Episode,
 func downloadStream(url, filename string) tea.Cmd {
 	return func() tea.Msg {
 		// Ensure downloads directory exists
-		if err := os.MkdirAll("downloads", 0755); err != nil {
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
 			return downloadCompleteMsg{filename: filename, err: err}
 		}
 
@@ -149,11 +599,30 @@ func downloadStream(url, filename string) tea.Cmd {
 	}
 }
 
-// Download with progress reporting via package-level program reference
-func downloadStreamWithProgress(id int, url, filename string, cancelChan chan struct{}) tea.Cmd {
+// Download with progress reporting via package-level program reference.
+// Torrent streams are downloaded from the debrid service's link.
+func downloadStreamWithProgress(id int, svc debrid.Service, stream apiutils.AlcSearchResult, filename string, cancelChan chan struct{}) tea.Cmd {
 	return func() tea.Msg {
+		url, headers := stream.Url, stream.BehaviorHints.ProxyHeaders.Request
+		if stream.Kind() == apiutils.StreamTorrent && svc != nil {
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				select {
+				case <-cancelChan:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+			resolved, err := resolveTorrent(ctx, svc, stream)
+			cancel()
+			if err != nil {
+				return downloadCompleteMsg{id: id, filename: filename, err: err}
+			}
+			url = resolved.Url
+		}
+
 		// Ensure downloads directory exists
-		if err := os.MkdirAll("downloads", 0755); err != nil {
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
 			return downloadCompleteMsg{id: id, filename: filename, err: err}
 		}
 
@@ -162,6 +631,10 @@ func downloadStreamWithProgress(id int, url, filename string, cancelChan chan st
 		if err != nil {
 			return downloadCompleteMsg{id: id, filename: filename, err: err}
 		}
+		// Some streams only work with the headers the addon asked for
+		for k, v := range headers {
+			req.HTTPRequest.Header.Set(k, v)
+		}
 
 		resp := client.Do(req)
 
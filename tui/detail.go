@@ -0,0 +1,215 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+
+	config "github.com/rshero/stremio-tui/config"
+	apiutils "github.com/rshero/stremio-tui/utils"
+)
+
+// openDetail shows what is known about a title right away and fetches the
+// rest of its details.
+func (m Model) openDetail(result apiutils.ImdbSearchResult, from View) (tea.Model, tea.Cmd) {
+	m.selectedTitle = &result
+	m.selectedSeason = nil
+	m.selectedEpisode = nil
+	m.detailFrom = from
+	m.detailLoading = true
+	m.view = DetailView
+	m.errorMsg = ""
+	m.statusMsg = ""
+	ctx := m.newRequest()
+	return m, tea.Batch(m.spinner.Tick, fetchTitleDetails(ctx, m.api, result))
+}
+
+func (m Model) updateDetailView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	title := *m.selectedTitle
+	isSeries := title.StremioType() == "series"
+
+	switch msg.String() {
+	case "esc":
+		m.cancelRequests()
+		m.detailLoading = false
+		m.view = m.detailFrom
+		m.statusMsg = ""
+		m.errorMsg = ""
+		if m.view == ResultsView && m.showingWatchlist {
+			// Titles may have been taken off the watchlist meanwhile
+			m.setWatchlistItems()
+		}
+		return m, nil
+	case "enter":
+		m.browseView = DetailView
+		m.statusMsg = ""
+		return m.selectTitle(title)
+	case "e":
+		if !isSeries {
+			m.statusMsg = "Movies have no episodes - press s for streams"
+			return m, nil
+		}
+		m.browseView = DetailView
+		m.statusMsg = ""
+		return m.selectTitle(title)
+	case "s":
+		if isSeries {
+			m.statusMsg = "Streams are listed per episode - press " + keyFor(config.ScopeDetail, "episodes") + " to pick one"
+			return m, nil
+		}
+		m.browseView = DetailView
+		m.statusMsg = ""
+		return m.selectTitle(title)
+	case "P", "D":
+		m.browseView = DetailView
+		return m.autoPickTitle(title, msg.String())
+	case "t":
+		if m.offline {
+			m.errorMsg = "Trailers are unavailable offline"
+			return m, nil
+		}
+		if m.player == nil {
+			return m.noPlayer()
+		}
+		m.errorMsg = ""
+		m.statusMsg = "Looking for a trailer..."
+		m.detailLoading = false
+		ctx := m.newRequest()
+		return m, playTrailer(ctx, m.api, m.player, title)
+	case "w":
+		if m.watchlist == nil {
+			m.errorMsg = "Watchlist unavailable: " + m.watchlistErr.Error()
+			return m, nil
+		}
+		added, err := m.watchlist.Toggle(title)
+		if err != nil {
+			m.errorMsg = "Failed to save watchlist: " + err.Error()
+			return m, nil
+		}
+		m.errorMsg = ""
+		if added {
+			m.statusMsg = "Added to watchlist"
+		} else {
+			m.statusMsg = "Removed from watchlist"
+		}
+		return m, nil
+	}
+	return m, nil
+}
+
+// showWatchlist lists the saved titles in the results view.
+func (m Model) showWatchlist() (tea.Model, tea.Cmd) {
+	if m.watchlist == nil {
+		m.errorMsg = "Watchlist unavailable: " + m.watchlistErr.Error()
+		return m, nil
+	}
+	if len(m.watchlist.Titles) == 0 {
+		m.errorMsg = "Your watchlist is empty - press " + keyFor(config.ScopeDetail, "watchlist") + " on a title to add it"
+		return m, nil
+	}
+	m.cancelRequests()
+	m.showingWatchlist = true
+	m.lastQuery = ""
+	m.searchMore = false
+	m.setWatchlistItems()
+	m.resultsList.ResetSelected()
+	m.view = ResultsView
+	m.errorMsg = ""
+	m.statusMsg = ""
+	return m, nil
+}
+
+func (m *Model) setWatchlistItems() {
+	m.imdbResults = append([]apiutils.ImdbSearchResult{}, m.watchlist.Titles...)
+	items := make([]list.Item, len(m.imdbResults))
+	for i, r := range m.imdbResults {
+		items[i] = imdbItem{result: r}
+	}
+	m.resultsList.SetItems(items)
+}
+
+// openTitleLink jumps to what a pasted link names: the streams of a movie or
+// episode, or a series' seasons or episodes. Going back leads through the
+// title's details to the search box.
+func (m Model) openTitleLink(msg titleLinkMsg) (tea.Model, tea.Cmd) {
+	title := msg.title
+	link := msg.link
+	m.selectedTitle = &title
+	m.selectedSeason = nil
+	m.selectedEpisode = nil
+	m.detailFrom = SearchView
+	m.browseView = DetailView
+	m.errorMsg = ""
+
+	if title.StremioType() != "series" || len(msg.seasons) == 0 {
+		id := title.Id
+		if link.VideoId != "" {
+			id = link.VideoId
+		}
+		m.loading = true
+		m.loadingMsg = "Fetching streams..."
+		cmd := m.loadStreams(title.StremioType(), id)
+		return m, tea.Batch(m.spinner.Tick, cmd)
+	}
+
+	m.seasons = msg.seasons
+	seasonItems := make([]list.Item, len(msg.seasons))
+	for i, s := range msg.seasons {
+		seasonItems[i] = seasonItem{result: s}
+	}
+	m.seasonsList.SetItems(seasonItems)
+	m.seasonsList.ResetSelected()
+	m.view = SeasonsView
+	if link.Season == 0 {
+		return m, nil
+	}
+
+	season := strconv.Itoa(link.Season)
+	for i := range m.seasons {
+		if m.seasons[i].Season == season {
+			m.seasonsList.Select(i)
+			m.selectedSeason = &m.seasons[i]
+		}
+	}
+	if m.selectedSeason == nil {
+		m.errorMsg = fmt.Sprintf("%s has no season %d", title.PrimaryTitle, link.Season)
+		return m, nil
+	}
+
+	m.episodes = msg.episodes
+	episodeItems := make([]list.Item, len(msg.episodes))
+	for i, e := range msg.episodes {
+		episodeItems[i] = episodeItem{result: e}
+	}
+	m.allEpisodeItems = episodeItems
+	m.episodesList.SetItems(episodeItems)
+	m.episodesList.ResetSelected()
+	m.isFiltering = false
+	m.filterInput.SetValue("")
+	m.view = EpisodesView
+	if link.Episode == 0 {
+		return m, nil
+	}
+
+	for i := range m.episodes {
+		if m.episodes[i].EpisodeNumber == link.Episode {
+			m.episodesList.Select(i)
+			m.selectedEpisode = &m.episodes[i]
+		}
+	}
+	if m.selectedEpisode == nil {
+		m.errorMsg = fmt.Sprintf("Season %d has no episode %d", link.Season, link.Episode)
+		return m, nil
+	}
+
+	streamId := m.selectedEpisode.StreamId(title.Id)
+	if link.VideoId != "" {
+		streamId = link.VideoId
+	}
+	m.loading = true
+	m.loadingMsg = "Fetching streams..."
+	cmd := m.loadStreams("series", streamId)
+	return m, tea.Batch(m.spinner.Tick, cmd)
+}
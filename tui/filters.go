@@ -0,0 +1,264 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	apiutils "github.com/rshero/stremio-tui/utils"
+)
+
+// typeFilters are the title type choices, cycled with left/right
+var typeFilters = []struct {
+	label string
+	types []string
+}{
+	{"Any type", nil},
+	{"Movies", []string{"movie", "tvMovie"}},
+	{"Series", []string{"tvSeries", "tvMiniSeries"}},
+	{"Shorts", []string{"short"}},
+	{"Specials", []string{"tvSpecial"}},
+}
+
+// Fields of the filter editor; the text fields follow the type field
+const (
+	filterFieldType = iota
+	filterFieldFrom
+	filterFieldTo
+	filterFieldRating
+	filterFieldCount
+)
+
+func newFilterFields() [3]textinput.Model {
+	var fields [3]textinput.Model
+	for i, prompt := range []string{"From year:  ", "To year:    ", "Min rating: "} {
+		fi := textinput.New()
+		fi.Prompt = prompt
+		fi.CharLimit = 4
+		fi.Width = 6
+		fi.PromptStyle = NormalStyle
+		fi.TextStyle = NormalStyle
+		fi.PlaceholderStyle = DimStyle
+		fields[i] = fi
+	}
+	fields[0].Placeholder = "1990"
+	fields[1].Placeholder = "1999"
+	fields[2].Placeholder = "7.5"
+	return fields
+}
+
+// openFilters starts editing the search filters from their current values.
+func (m Model) openFilters() (tea.Model, tea.Cmd) {
+	f := m.searchFilter
+	m.editingFilters = true
+	m.filterField = filterFieldType
+	m.filterTypeIdx = 0
+	for i, t := range typeFilters {
+		if strings.Join(t.types, ",") == strings.Join(f.Types, ",") {
+			m.filterTypeIdx = i
+		}
+	}
+	m.filterFields = newFilterFields()
+	if f.StartYear > 0 {
+		m.filterFields[0].SetValue(strconv.Itoa(f.StartYear))
+	}
+	if f.EndYear > 0 {
+		m.filterFields[1].SetValue(strconv.Itoa(f.EndYear))
+	}
+	if f.MinRating > 0 {
+		m.filterFields[2].SetValue(strconv.FormatFloat(f.MinRating, 'f', -1, 64))
+	}
+	m.searchInput.Blur()
+	m.errorMsg = ""
+	return m, nil
+}
+
+func (m Model) updateFilterEditor(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.closeFilters()
+		return m, nil
+	case "up":
+		if m.filterField > 0 {
+			m.filterField--
+		}
+		return m, m.focusFilterField()
+	case "down":
+		if m.filterField < filterFieldCount-1 {
+			m.filterField++
+		}
+		return m, m.focusFilterField()
+	case "left", "right":
+		if m.filterField == filterFieldType {
+			step := 1
+			if msg.String() == "left" {
+				step = len(typeFilters) - 1
+			}
+			m.filterTypeIdx = (m.filterTypeIdx + step) % len(typeFilters)
+			return m, nil
+		}
+	case "ctrl+u":
+		// Clear every filter
+		m.filterTypeIdx = 0
+		for i := range m.filterFields {
+			m.filterFields[i].SetValue("")
+		}
+		return m, nil
+	case "enter":
+		filter, err := m.draftFilter()
+		if err != nil {
+			m.errorMsg = err.Error()
+			return m, nil
+		}
+		return m.applyFilters(filter)
+	}
+
+	if m.filterField == filterFieldType {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	i := m.filterField - filterFieldFrom
+	m.filterFields[i], cmd = m.filterFields[i].Update(msg)
+	return m, cmd
+}
+
+// focusFilterField moves the cursor to the selected text field.
+func (m *Model) focusFilterField() tea.Cmd {
+	for i := range m.filterFields {
+		m.filterFields[i].Blur()
+	}
+	if m.filterField == filterFieldType {
+		return nil
+	}
+	return m.filterFields[m.filterField-filterFieldFrom].Focus()
+}
+
+func (m *Model) closeFilters() {
+	m.editingFilters = false
+	m.errorMsg = ""
+	if m.view == SearchView {
+		m.searchInput.Focus()
+	}
+}
+
+// draftFilter reads the filter being edited.
+func (m Model) draftFilter() (apiutils.SearchFilter, error) {
+	f := apiutils.SearchFilter{Types: typeFilters[m.filterTypeIdx].types}
+
+	year := func(field textinput.Model, name string) (int, error) {
+		v := strings.TrimSpace(field.Value())
+		if v == "" {
+			return 0, nil
+		}
+		y, err := strconv.Atoi(v)
+		if err != nil || y < 1870 || y > 2100 {
+			return 0, fmt.Errorf("%s must be a year like 1994", name)
+		}
+		return y, nil
+	}
+	var err error
+	if f.StartYear, err = year(m.filterFields[0], "From year"); err != nil {
+		return f, err
+	}
+	if f.EndYear, err = year(m.filterFields[1], "To year"); err != nil {
+		return f, err
+	}
+	if f.StartYear > 0 && f.EndYear > 0 && f.StartYear > f.EndYear {
+		return f, fmt.Errorf("From year is after To year")
+	}
+	if v := strings.TrimSpace(m.filterFields[2].Value()); v != "" {
+		r, err := strconv.ParseFloat(v, 64)
+		if err != nil || r < 0 || r > 10 {
+			return f, fmt.Errorf("Min rating must be between 0 and 10")
+		}
+		f.MinRating = r
+	}
+	return f, nil
+}
+
+// applyFilters sets the filters and, on the results screen, searches again
+// with them.
+func (m Model) applyFilters(filter apiutils.SearchFilter) (tea.Model, tea.Cmd) {
+	m.searchFilter = filter
+	m.closeFilters()
+	if m.view != ResultsView || m.lastQuery == "" {
+		return m, nil
+	}
+	m.loading = true
+	m.loadingMsg = "Searching..."
+	m.emptyPages = 0
+	ctx := m.newRequest()
+	return m, tea.Batch(m.spinner.Tick, searchIMDB(ctx, m.api, m.lastQuery, m.searchFilter, apiutils.SearchCursor{}))
+}
+
+// filterChips lists the active filters
+func (m Model) filterChips() []string {
+	f := m.searchFilter
+	var chips []string
+	for _, t := range typeFilters[1:] {
+		if strings.Join(t.types, ",") == strings.Join(f.Types, ",") {
+			chips = append(chips, t.label)
+		}
+	}
+	switch {
+	case f.StartYear > 0 && f.EndYear > 0:
+		chips = append(chips, fmt.Sprintf("%d–%d", f.StartYear, f.EndYear))
+	case f.StartYear > 0:
+		chips = append(chips, fmt.Sprintf("%d+", f.StartYear))
+	case f.EndYear > 0:
+		chips = append(chips, fmt.Sprintf("until %d", f.EndYear))
+	}
+	if f.MinRating > 0 {
+		chips = append(chips, fmt.Sprintf("★ %s+", strconv.FormatFloat(f.MinRating, 'f', -1, 64)))
+	}
+	return chips
+}
+
+// renderFilterChips draws the active filters on one line, or nothing.
+func (m Model) renderFilterChips() string {
+	chips := m.filterChips()
+	if len(chips) == 0 {
+		return ""
+	}
+	rendered := make([]string, len(chips))
+	for i, c := range chips {
+		rendered[i] = ChipStyle.Render(c)
+	}
+	return strings.Join(rendered, " ") + "\n"
+}
+
+// filterNote explains an empty search when filters are active
+func (m Model) filterNote() string {
+	if m.searchFilter.IsZero() {
+		return ""
+	}
+	return " with the current filters"
+}
+
+func (m Model) renderFilterEditor() string {
+	var b strings.Builder
+	b.WriteString(TitleStyle.Render("Search filters") + "\n")
+
+	cursor := func(field int) string {
+		if m.filterField == field {
+			return SelectedStyle.Render("› ")
+		}
+		return "  "
+	}
+	typeLine := NormalStyle.Render("Type:       ") + "‹ " + typeFilters[m.filterTypeIdx].label + " ›"
+	b.WriteString(cursor(filterFieldType) + typeLine + "\n")
+	for i, field := range m.filterFields {
+		b.WriteString(cursor(filterFieldFrom+i) + field.View() + "\n")
+	}
+
+	if m.errorMsg != "" {
+		b.WriteString("\n" + ErrorStyle.Render(m.errorMsg) + "\n")
+	}
+	b.WriteString(HelpStyle.Render("↑/↓: field • ←/→: type • ctrl+u: clear • enter: apply • esc: cancel"))
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, BoxStyle.Render(b.String()))
+}
@@ -0,0 +1,103 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	config "github.com/rshero/stremio-tui/config"
+)
+
+// keyAliases maps, per scope, the keys bound in the config to the key the
+// scope's handler matches for their action. The default keys keep working.
+var keyAliases = map[string]map[string]string{}
+
+// boundKeys maps, per scope, actions to the key bound to them there, for
+// the help lines.
+var boundKeys = map[string]map[string]string{}
+
+// setKeyBindings reads the action -> key bindings from the config. Letters
+// aren't bound in the search box, where they are typed into the query.
+func setKeyBindings(bindings map[string]string) {
+	keyAliases = map[string]map[string]string{}
+	boundKeys = map[string]map[string]string{}
+	for action, key := range bindings {
+		for _, scope := range config.KeyScopes[action] {
+			def := config.DefaultKey(scope, action)
+			if key == def || (scope == config.ScopeSearch && config.IsTextKey(key)) {
+				continue
+			}
+			if keyAliases[scope] == nil {
+				keyAliases[scope] = map[string]string{}
+				boundKeys[scope] = map[string]string{}
+			}
+			keyAliases[scope][key] = def
+			boundKeys[scope][action] = key
+		}
+	}
+}
+
+// keyFor returns the key that triggers action in scope, for help lines.
+func keyFor(scope, action string) string {
+	if key, ok := boundKeys[scope][action]; ok {
+		return key
+	}
+	return config.DefaultKey(scope, action)
+}
+
+// keyMsg builds the key event whose String() is key.
+func keyMsg(key string) tea.KeyMsg {
+	if len(key) == len("ctrl+a") && key[:5] == "ctrl+" && key[5] >= 'a' && key[5] <= 'z' {
+		return tea.KeyMsg{Type: tea.KeyCtrlA + tea.KeyType(key[5]-'a')}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+// keyScope returns the scope keys are remapped in, or "" while typing in a
+// text input other than the search box.
+func (m Model) keyScope() string {
+	switch m.currentTab {
+	case DownloadsTab:
+		return config.ScopeDownloads
+	case AddonsTab:
+		if m.addingAddon {
+			return ""
+		}
+		return config.ScopeAddons
+	}
+	if m.editingFilters || m.isFiltering {
+		return ""
+	}
+	switch m.view {
+	case SearchView:
+		return config.ScopeSearch
+	case ResultsView:
+		return config.ScopeResults
+	case DetailView:
+		return config.ScopeDetail
+	case CatalogsView:
+		return config.ScopeCatalogs
+	case CatalogItemsView:
+		return config.ScopeCatalogItems
+	case SeasonsView:
+		return config.ScopeSeasons
+	case EpisodesView:
+		return config.ScopeEpisodes
+	case StreamsView:
+		return config.ScopeStreams
+	case BatchSelectView:
+		return config.ScopeBatchSelect
+	}
+	return ""
+}
+
+// translateKey replaces a rebound key with the key its action's handler
+// matches in the current view.
+func (m Model) translateKey(msg tea.KeyMsg) tea.KeyMsg {
+	scope := m.keyScope()
+	if scope == config.ScopeSearch && (msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace) {
+		return msg
+	}
+	if def, ok := keyAliases[scope][msg.String()]; ok {
+		return keyMsg(def)
+	}
+	return msg
+}
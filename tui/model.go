@@ -1,9 +1,11 @@
 package tui
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/progress"
@@ -11,7 +13,14 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 
+	config "github.com/rshero/stremio-tui/config"
+	"github.com/rshero/stremio-tui/debrid"
+	"github.com/rshero/stremio-tui/parser"
+	"github.com/rshero/stremio-tui/player"
+	"github.com/rshero/stremio-tui/quality"
+	"github.com/rshero/stremio-tui/torrent"
 	apiutils "github.com/rshero/stremio-tui/utils"
+	"github.com/rshero/stremio-tui/watchlist"
 )
 
 type View int
@@ -19,9 +28,12 @@ type View int
 const (
 	SearchView View = iota
 	ResultsView
+	CatalogsView
+	CatalogItemsView
 	SeasonsView
 	EpisodesView
 	StreamsView
+	DetailView
 	BatchInputView
 	BatchSelectView
 )
@@ -31,6 +43,8 @@ type Tab int
 const (
 	MainTab Tab = iota
 	DownloadsTab
+	AddonsTab
+	tabCount
 )
 
 type DownloadStatus int
@@ -41,6 +55,7 @@ const (
 	DownloadComplete
 	DownloadFailed
 	DownloadCancelled
+	DownloadHandedOff // sent to a torrent client that doesn't report progress
 )
 
 type Download struct {
@@ -52,6 +67,8 @@ type Download struct {
 	Status     DownloadStatus
 	Error      error
 	CancelChan chan struct{}
+	Client     string // torrent client the download was handed to
+	Torrent    string // its id in that client, polled for progress
 }
 
 // BatchStream represents a stream in batch download selection
@@ -70,10 +87,21 @@ type BatchFailure struct {
 // List item implementations
 type imdbItem struct {
 	result apiutils.ImdbSearchResult
+	stale  bool
 }
 
 func (i imdbItem) Title() string { return i.result.PrimaryTitle }
 func (i imdbItem) Description() string {
+	desc := i.typeLabel()
+	if years := i.result.Years(); years != "" {
+		desc += " • " + years
+	}
+	if r := i.result.Rating; r != nil && r.AggregateRating > 0 {
+		desc += fmt.Sprintf(" • ★ %.1f", r.AggregateRating)
+	}
+	return staleSuffix(desc, i.stale)
+}
+func (i imdbItem) typeLabel() string {
 	switch i.result.Type {
 	case "tvSeries", "tvMiniSeries":
 		return "TV"
@@ -89,38 +117,157 @@ func (i imdbItem) Description() string {
 		return i.result.Type
 	}
 }
-func (i imdbItem) FilterValue() string { return i.result.PrimaryTitle + " " + i.result.OriginalTitle + " " + i.result.Type }
+func (i imdbItem) FilterValue() string {
+	return i.result.PrimaryTitle + " " + i.result.OriginalTitle + " " + i.result.Type
+}
+
+type catalogItem struct {
+	ref apiutils.CatalogRef
+}
+
+func (i catalogItem) Title() string {
+	if i.ref.Catalog.Name != "" {
+		return i.ref.Catalog.Name
+	}
+	return i.ref.Catalog.Id
+}
+func (i catalogItem) Description() string {
+	desc := typeLabel(i.ref.Catalog.Type) + " • " + i.ref.Addon.Name()
+	if genres := i.ref.Genres(); len(genres) > 0 {
+		desc += fmt.Sprintf(" • %d genres", len(genres))
+	}
+	return desc
+}
+func (i catalogItem) FilterValue() string {
+	return i.ref.Catalog.Name + " " + i.ref.Catalog.Type + " " + i.ref.Addon.Name()
+}
+
+type metaItem struct {
+	meta apiutils.MetaPreview
+}
+
+func (i metaItem) Title() string { return i.meta.Name }
+func (i metaItem) Description() string {
+	desc := typeLabel(i.meta.Type)
+	if i.meta.ReleaseInfo != "" {
+		desc += " • " + i.meta.ReleaseInfo
+	}
+	return desc
+}
+func (i metaItem) FilterValue() string { return i.meta.Name }
 
 type streamItem struct {
 	result apiutils.AlcSearchResult
+	info   parser.StreamInfo
+	order  int // position in the addon results, for stable sorting
+	stale  bool
+
+	// Rating by the active quality profile
+	score    int
+	rejected bool
+	best     bool
+}
+
+func newStreamItem(result apiutils.AlcSearchResult, order int, stale bool) streamItem {
+	hints := result.BehaviorHints
+	return streamItem{
+		result: result,
+		info:   parser.Parse(result.Name, result.Details(), hints.Filename, hints.VideoSize),
+		order:  order,
+		stale:  stale,
+	}
 }
 
 func (i streamItem) Title() string {
-	title := i.result.Name
-	// Add size to title if available for visibility
-	if i.result.BehaviorHints.VideoSize > 0 {
-		title += " [" + formatSize(i.result.BehaviorHints.VideoSize) + "]"
+	// Parsed quality as aligned columns, then the addon's name for the stream
+	title := streamColumns(i.info) + "  " + strings.Join(strings.Fields(i.result.Name), " ")
+	if i.best {
+		title = "★ " + title
+	}
+	// Tag streams that don't play through the player directly
+	switch i.result.Kind() {
+	case apiutils.StreamTorrent:
+		title += " (torrent)"
+	case apiutils.StreamYouTube:
+		title += " (YouTube)"
+	case apiutils.StreamExternal:
+		title += " (external)"
 	}
 	return title
 }
 func (i streamItem) Description() string {
-	return i.result.Description
+	desc := i.result.Details()
+	if i.result.Addon != "" {
+		desc = "[" + i.result.Addon + "] " + desc
+	}
+	if countries := i.result.BehaviorHints.CountryWhitelist; len(countries) > 0 {
+		desc += " • only " + strings.ToUpper(strings.Join(countries, ", "))
+	}
+	if i.best {
+		desc += " • best match"
+	} else if i.rejected {
+		desc += " • excluded by profile"
+	}
+	return staleSuffix(desc, i.stale)
 }
 func (i streamItem) FilterValue() string {
-	// Search through name, description, and filename
-	return i.result.Name + " " + i.result.Description + " " + i.result.BehaviorHints.Filename
+	// Search through addon, name, description, filename, kind and parsed info
+	return i.result.Addon + " " + i.result.Name + " " + i.result.Details() + " " + i.result.BehaviorHints.Filename + " " + i.result.Kind().String() +
+		" " + i.info.Resolution + " " + i.info.Source + " " + i.info.VideoCodec + " " + strings.Join(i.info.HDR, " ") +
+		" " + i.info.AudioCodec + " " + strings.Join(i.info.Languages, " ") + " " + i.info.Group
+}
+
+// text is what the parser and quality profiles read the stream from
+func (i streamItem) text() string {
+	return i.result.Name + "\n" + i.result.Details() + "\n" + i.result.BehaviorHints.Filename
+}
+
+// streamColumns renders parsed stream info as fixed-width columns so the
+// stream list lines up
+func streamColumns(info parser.StreamInfo) string {
+	orDash := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return s
+	}
+	cached := " "
+	if info.Cached {
+		cached = "⚡"
+	}
+	audio := strings.TrimSpace(info.AudioCodec + " " + info.Channels)
+	size, seeders := "-", "-"
+	if info.Size > 0 {
+		size = formatSize(info.Size)
+	}
+	if info.Seeders > 0 {
+		seeders = fmt.Sprintf("%d", info.Seeders)
+	}
+	return fmt.Sprintf("%s %-5s %-6s %-4s %-9s %-13s %9s %5s",
+		cached,
+		orDash(info.Resolution),
+		orDash(info.Source),
+		orDash(info.VideoCodec),
+		orDash(strings.Join(info.HDR, "/")),
+		orDash(audio),
+		size,
+		seeders)
 }
 
 type seasonItem struct {
 	result apiutils.Season
+	stale  bool
 }
 
-func (i seasonItem) Title() string       { return "Season " + i.result.Season }
-func (i seasonItem) Description() string { return fmt.Sprintf("%d episodes", i.result.EpisodeCount) }
+func (i seasonItem) Title() string { return "Season " + i.result.Season }
+func (i seasonItem) Description() string {
+	return staleSuffix(fmt.Sprintf("%d episodes", i.result.EpisodeCount), i.stale)
+}
 func (i seasonItem) FilterValue() string { return i.result.Season }
 
 type episodeItem struct {
 	result apiutils.Episode
+	stale  bool
 }
 
 func (i episodeItem) Title() string {
@@ -134,7 +281,7 @@ func (i episodeItem) Description() string {
 	if i.result.Rating.AggregateRating > 0 {
 		desc = fmt.Sprintf("★ %.1f • %s", i.result.Rating.AggregateRating, desc)
 	}
-	return desc
+	return staleSuffix(desc, i.stale)
 }
 func (i episodeItem) FilterValue() string {
 	return fmt.Sprintf("%d %s %s", i.result.EpisodeNumber, i.result.Title, i.result.Plot)
@@ -142,30 +289,87 @@ func (i episodeItem) FilterValue() string {
 
 // Main model
 type Model struct {
-	view        View
-	currentTab  Tab
-	searchInput textinput.Model
-	resultsList list.Model
-	seasonsList list.Model
-	episodesList list.Model
-	streamsList list.Model
-	spinner     spinner.Model
-	progress    progress.Model
+	view             View
+	currentTab       Tab
+	searchInput      textinput.Model
+	resultsList      list.Model
+	catalogsList     list.Model
+	catalogItemsList list.Model
+	seasonsList      list.Model
+	episodesList     list.Model
+	streamsList      list.Model
+	spinner          spinner.Model
+	progress         progress.Model
 
 	// Data
-	imdbResults     []apiutils.ImdbSearchResult
-	seasons         []apiutils.Season
-	episodes        []apiutils.Episode
-	streams         []apiutils.AlcSearchResult
+	imdbResults    []apiutils.ImdbSearchResult
+	seasons        []apiutils.Season
+	episodes       []apiutils.Episode
+	streams        []apiutils.AlcSearchResult
+	streamFailures []apiutils.AddonError // addons that failed for the current streams
+	streamsType    string                // content type and id the streams were fetched for
+	streamsId      string
+	subtitles      []apiutils.Subtitle
+
+	// Subtitle language picker; chosen languages persist across titles
+	subLangs        []string
+	pickingSubs     bool
+	subPickerIdx    int
 	selectedTitle   *apiutils.ImdbSearchResult
 	selectedSeason  *apiutils.Season
 	selectedEpisode *apiutils.Episode
 	selectedStream  *apiutils.AlcSearchResult
 
+	lastQuery string
+
+	// Search-as-you-type suggestions. suggestSeq numbers each edit so only
+	// the reply for the latest one is shown; suggestIdx is -1 in the input.
+	suggestions   []apiutils.ImdbSearchResult
+	suggestIdx    int
+	suggestSeq    int
+	cancelSuggest context.CancelFunc
+
+	// Search paging
+	searchNext  apiutils.SearchCursor
+	searchMore  bool
+	loadingMore bool
+	emptyPages  int // pages in a row the filters left empty
+
+	// Search filters and their editor
+	searchFilter   apiutils.SearchFilter
+	editingFilters bool
+	filterField    int
+	filterTypeIdx  int // draft title type while editing
+	filterFields   [3]textinput.Model
+
+	// Catalog browsing
+	catalogs        []apiutils.CatalogRef
+	catalogMetas    []apiutils.MetaPreview
+	selectedCatalog *apiutils.CatalogRef
+	catalogGenre    string
+	browseView      View // list the selected title was picked from
+
+	// Title detail view; detailFrom is the view it was opened from
+	detailFrom       View
+	detailLoading    bool
+	watchlist        *watchlist.List
+	watchlistErr     error
+	showingWatchlist bool
+
 	// Store all items for manual filtering
 	allEpisodeItems []list.Item
 	allStreamItems  []list.Item
 
+	// Stream ordering, kept across titles
+	streamSort     StreamSort
+	streamSortDesc bool
+
+	// Quality profiles; profileIdx is -1 when none is active. autoPick is
+	// "play" or "download" while fetching streams to act on the best match.
+	profiles   []quality.Profile
+	profileIdx int
+	autoPick   string
+
 	// Custom filter state
 	filterInput textinput.Model
 	isFiltering bool
@@ -175,6 +379,14 @@ type Model struct {
 	nextDownloadID      int
 	selectedDownloadIdx int
 
+	// Addon manager; manifests and load errors are keyed by addon URL
+	addonIdx       int
+	addonManifests map[string]*apiutils.Addon
+	addonErrs      map[string]error
+	addingAddon    bool
+	checkingAddon  bool
+	addonInput     textinput.Model
+
 	// Batch download state
 	batchInput       textinput.Model
 	batchStreams     []BatchStream
@@ -190,11 +402,32 @@ type Model struct {
 	statusMsg string
 	errorMsg  string
 
+	// API client and cancellation of the current view's requests
+	api           *apiutils.Client
+	cancelRequest context.CancelFunc
+	offline       bool
+
+	// Debrid service torrents are resolved through, else the torrent client
+	// they're handed to; with neither, magnets go to the system handler
+	debrid          debrid.Service
+	torrents        torrent.Client
+	pollingTorrents bool
+
+	// Video player; nil with playerErr set when it couldn't be found
+	player    player.Player
+	playerErr error
+
 	// Dimensions
 	width, height int
 }
 
-func NewModel() Model {
+// NewModel creates the TUI model. In offline mode every request is served
+// from the response cache and files already in downloads/ are listed.
+func NewModel(offline bool) Model {
+	// Styles are built before the config file is read
+	SetTheme(config.THEME)
+	setKeyBindings(config.KEYBINDINGS)
+
 	// Search input
 	ti := textinput.New()
 	ti.Placeholder = "Search for movies or shows..."
@@ -231,6 +464,20 @@ func NewModel() Model {
 	resultsList.SetFilteringEnabled(false)
 	resultsList.Styles.Title = TitleStyle.Padding(0, 0, 1, 2)
 
+	// Catalogs list
+	catalogsList := list.New([]list.Item{}, createDelegate(), 0, 0)
+	catalogsList.Title = "Catalogs"
+	catalogsList.SetShowStatusBar(false)
+	catalogsList.SetFilteringEnabled(false)
+	catalogsList.Styles.Title = TitleStyle.Padding(0, 0, 1, 2)
+
+	// Catalog items list
+	catalogItemsList := list.New([]list.Item{}, createDelegate(), 0, 0)
+	catalogItemsList.Title = "Browse"
+	catalogItemsList.SetShowStatusBar(true)
+	catalogItemsList.SetFilteringEnabled(false)
+	catalogItemsList.Styles.Title = TitleStyle.Padding(0, 0, 1, 2)
+
 	// Seasons list
 	seasonsList := list.New([]list.Item{}, createDelegate(), 0, 0)
 	seasonsList.Title = "Select Season"
@@ -270,19 +517,84 @@ func NewModel() Model {
 	bi.TextStyle = NormalStyle
 	bi.PlaceholderStyle = DimStyle
 
+	// Addon URL input
+	ai := textinput.New()
+	ai.Placeholder = "https://example.com/manifest.json"
+	ai.Width = 60
+	ai.Prompt = "Addon URL: "
+	ai.PromptStyle = SelectedStyle
+	ai.TextStyle = NormalStyle
+	ai.PlaceholderStyle = DimStyle
+
+	api := apiutils.NewDefaultClient()
+	api.SetOffline(offline)
+
+	downloads := []Download{}
+	if offline {
+		downloads = existingDownloads(config.DOWNLOAD_DIR)
+	}
+
+	// Setup problems are shown together on the first screen
+	var problems []string
+
+	// The named profile, or the first one when none is named
+	profiles, err := quality.Load(config.QUALITY_PROFILES)
+	if err != nil {
+		problems = append(problems, "Failed to load quality profiles: "+err.Error())
+	}
+	profileIdx := quality.Find(profiles, config.QUALITY_PROFILE)
+	if config.QUALITY_PROFILE == "" && len(profiles) > 0 {
+		profileIdx = 0
+	} else if profileIdx < 0 && err == nil && config.QUALITY_PROFILE != "" {
+		problems = append(problems, fmt.Sprintf("Unknown quality profile %q", config.QUALITY_PROFILE))
+	}
+
+	wl, wlErr := watchlist.Open("")
+
+	svc, err := debrid.New(config.DEBRID, config.HTTP_TIMEOUT)
+	if err != nil {
+		problems = append(problems, "Failed to set up debrid: "+err.Error())
+	}
+	torrents, err := torrent.New(config.TORRENT, config.HTTP_TIMEOUT)
+	if err != nil {
+		problems = append(problems, "Failed to set up the torrent client: "+err.Error())
+	}
+	vp, playerErr := player.New(config.PLAYER)
+	if playerErr != nil {
+		problems = append(problems, "Player unavailable: "+playerErr.Error())
+	}
+
 	return Model{
-		view:         SearchView,
-		currentTab:   MainTab,
-		searchInput:  ti,
-		filterInput:  fi,
-		batchInput:   bi,
-		resultsList:  resultsList,
-		seasonsList:  seasonsList,
-		episodesList: episodesList,
-		streamsList:  streamsList,
-		spinner:      sp,
-		progress:     prog,
-		downloads:    []Download{},
+		view:             SearchView,
+		currentTab:       MainTab,
+		searchInput:      ti,
+		filterInput:      fi,
+		filterFields:     newFilterFields(),
+		batchInput:       bi,
+		addonInput:       ai,
+		resultsList:      resultsList,
+		catalogsList:     catalogsList,
+		catalogItemsList: catalogItemsList,
+		seasonsList:      seasonsList,
+		episodesList:     episodesList,
+		streamsList:      streamsList,
+		spinner:          sp,
+		progress:         prog,
+		downloads:        downloads,
+		nextDownloadID:   len(downloads),
+		browseView:       ResultsView,
+		suggestIdx:       -1,
+		api:              api,
+		offline:          offline,
+		debrid:           svc,
+		torrents:         torrents,
+		player:           vp,
+		playerErr:        playerErr,
+		watchlist:        wl,
+		watchlistErr:     wlErr,
+		profiles:         profiles,
+		profileIdx:       profileIdx,
+		errorMsg:         strings.Join(problems, "; "),
 	}
 }
 
@@ -298,31 +610,42 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		m.resultsList.SetSize(msg.Width-4, msg.Height-8)
+		m.catalogsList.SetSize(msg.Width-4, msg.Height-8)
+		m.catalogItemsList.SetSize(msg.Width-4, msg.Height-8)
 		m.seasonsList.SetSize(msg.Width-4, msg.Height-8)
 		m.episodesList.SetSize(msg.Width-4, msg.Height-8)
 		m.streamsList.SetSize(msg.Width-4, msg.Height-8)
 		return m, nil
 
 	case tea.KeyMsg:
+		msg = m.translateKey(msg)
+
 		// Global keys
 		switch msg.String() {
 		case "ctrl+c":
 			return m, tea.Quit
 		case "tab":
 			// Cycle between tabs (don't switch if filtering or loading)
-			if !m.isFiltering && !m.loading {
-				if m.currentTab == MainTab {
-					m.currentTab = DownloadsTab
-				} else {
-					m.currentTab = MainTab
+			if !m.isFiltering && !m.loading && !m.editingFilters && !m.addingAddon {
+				m.currentTab = (m.currentTab + 1) % tabCount
+				if m.currentTab == AddonsTab {
+					m.errorMsg = ""
+					m.statusMsg = ""
+					return m, m.loadAddons(false)
 				}
 				return m, nil
 			}
 		case "q":
-			if m.currentTab == DownloadsTab {
+			if m.addingAddon {
+				break
+			}
+			if m.currentTab != MainTab {
 				m.currentTab = MainTab
 				return m, nil
 			}
+			if m.editingFilters {
+				break
+			}
 			if m.view == SearchView && !m.searchInput.Focused() {
 				return m, tea.Quit
 			}
@@ -339,6 +662,25 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.currentTab == DownloadsTab {
 			return m.updateDownloadsTab(msg)
 		}
+		if m.currentTab == AddonsTab {
+			return m.updateAddonsTab(msg)
+		}
+
+		// Leaving a view while it is loading abandons its requests
+		if msg.String() == "esc" && m.loading {
+			m.cancelRequests()
+			m.loading = false
+			m.batchFetching = 0
+			m.autoPick = ""
+		}
+
+		if m.editingFilters {
+			return m.updateFilterEditor(msg)
+		}
+
+		if msg.String() == "ctrl+r" && !m.loading && !m.isFiltering {
+			return m.refreshView()
+		}
 
 		// Only process view-specific keys on Main tab
 		switch m.view {
@@ -346,12 +688,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateSearchView(msg)
 		case ResultsView:
 			return m.updateResultsView(msg)
+		case CatalogsView:
+			return m.updateCatalogsView(msg)
+		case CatalogItemsView:
+			return m.updateCatalogItemsView(msg)
 		case SeasonsView:
 			return m.updateSeasonsView(msg)
 		case EpisodesView:
 			return m.updateEpisodesView(msg)
 		case StreamsView:
 			return m.updateStreamsView(msg)
+		case DetailView:
+			return m.updateDetailView(msg)
 		case BatchInputView:
 			return m.updateBatchInputView(msg)
 		case BatchSelectView:
@@ -359,7 +707,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case spinner.TickMsg:
-		if m.loading {
+		if m.loading || m.loadingMore {
 			var cmd tea.Cmd
 			m.spinner, cmd = m.spinner.Update(msg)
 			cmds = append(cmds, cmd)
@@ -376,42 +724,185 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Custom messages
 	case searchResultsMsg:
 		m.loading = false
-		m.imdbResults = msg.results
+		m.loadingMore = false
+		if msg.query != m.lastQuery {
+			return m, nil
+		}
+		m.searchNext = msg.next
+		m.searchMore = msg.more
+
+		// The filters can empty out whole pages; look a few pages further
+		// before giving up
 		if len(msg.results) == 0 {
-			m.errorMsg = "No results found"
+			m.emptyPages++
+			if m.emptyPages >= maxEmptyPages {
+				m.searchMore = false
+			}
+		} else {
+			m.emptyPages = 0
+		}
+
+		if msg.appends {
+			// Later pages go below what's loaded, keeping the cursor where it is
+			items := m.resultsList.Items()
+			for _, r := range msg.results {
+				m.imdbResults = append(m.imdbResults, r)
+				items = append(items, imdbItem{result: r, stale: msg.stale})
+			}
+			m.resultsList.SetItems(items)
+			if len(msg.results) == 0 && m.searchMore {
+				return m.loadMoreResults()
+			}
+			if len(m.imdbResults) == 0 {
+				m.errorMsg = "No results found for \"" + msg.query + "\"" + m.filterNote()
+			}
+			return m, nil
+		}
+		m.imdbResults = msg.results
+		m.showingWatchlist = false
+		if len(msg.results) == 0 && !m.searchMore {
+			m.errorMsg = "No results found for \"" + msg.query + "\"" + m.filterNote()
 			return m, nil
 		}
 		items := make([]list.Item, len(msg.results))
 		for i, r := range msg.results {
-			items[i] = imdbItem{result: r}
+			items[i] = imdbItem{result: r, stale: msg.stale}
 		}
 		m.resultsList.SetItems(items)
+		m.resultsList.ResetSelected()
 		m.view = ResultsView
 		m.errorMsg = ""
+		if len(msg.results) == 0 {
+			return m.loadMoreResults()
+		}
 		return m, nil
 
 	case streamsResultsMsg:
 		m.loading = false
+		action := m.autoPick
+		m.autoPick = ""
 		m.streams = msg.results
+		m.streamFailures = msg.failures
 		if len(msg.results) == 0 {
 			m.errorMsg = "No streams found"
+			if len(msg.failures) > 0 {
+				reasons := make([]string, len(msg.failures))
+				for i, f := range msg.failures {
+					reasons[i] = f.Error()
+				}
+				m.errorMsg = "Failed to fetch streams: " + strings.Join(reasons, "; ")
+			}
 			// Go back to appropriate view
 			if m.selectedEpisode != nil {
 				m.view = EpisodesView
 			} else {
-				m.view = ResultsView
+				m.view = m.browseView
 			}
 			return m, nil
 		}
 		items := make([]list.Item, len(msg.results))
 		for i, r := range msg.results {
-			items[i] = streamItem{result: r}
+			items[i] = newStreamItem(r, i, msg.stale)
 		}
 		m.allStreamItems = items // Store for filtering
-		m.streamsList.SetItems(items)
-		m.view = StreamsView
 		m.isFiltering = false
+		m.pickingSubs = false
 		m.filterInput.SetValue("")
+		m.scoreStreams()
+		m.resortStreams()
+		m.errorMsg = ""
+		if action != "" {
+			return m.pickBest(action)
+		}
+		m.view = StreamsView
+		return m, nil
+
+	case suggestTickMsg:
+		// Typing went on since this tick was scheduled
+		if msg.seq != m.suggestSeq {
+			return m, nil
+		}
+		if m.cancelSuggest != nil {
+			m.cancelSuggest()
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		m.cancelSuggest = cancel
+		return m, fetchSuggestions(ctx, m.api, msg.seq, msg.query)
+
+	case titleDetailsMsg:
+		// Ignore details for a title we've already left
+		if m.selectedTitle == nil || msg.result.Id != m.selectedTitle.Id {
+			return m, nil
+		}
+		m.loading = false
+		m.detailLoading = false
+		m.selectedTitle = &msg.result
+		if msg.err != nil {
+			m.statusMsg = string(requestError("load details", msg.err))
+		}
+		return m, nil
+
+	case titleLinkMsg:
+		m.loading = false
+		return m.openTitleLink(msg)
+
+	case suggestionsMsg:
+		// Drop replies for older queries that arrive late
+		if msg.seq != m.suggestSeq || m.view != SearchView {
+			return m, nil
+		}
+		m.suggestions = msg.results
+		m.suggestIdx = -1
+		return m, nil
+
+	case subtitlesResultsMsg:
+		// Ignore late replies for a title we've already left
+		if msg.id == m.streamsId {
+			m.subtitles = msg.results
+		}
+		return m, nil
+
+	case catalogsResultsMsg:
+		m.loading = false
+		m.catalogs = msg.catalogs
+		if len(msg.catalogs) == 0 {
+			m.errorMsg = "No catalogs found"
+			if len(msg.failures) > 0 {
+				m.errorMsg = "Failed to load addons: " + msg.failures[0].Error()
+			}
+			return m, nil
+		}
+		items := make([]list.Item, len(msg.catalogs))
+		for i, c := range msg.catalogs {
+			items[i] = catalogItem{ref: c}
+		}
+		m.catalogsList.SetItems(items)
+		m.view = CatalogsView
+		m.errorMsg = ""
+		return m, nil
+
+	case catalogItemsMsg:
+		m.loading = false
+		if msg.skip == 0 {
+			if len(msg.metas) == 0 {
+				m.errorMsg = "Catalog is empty"
+				return m, nil
+			}
+			m.catalogMetas = msg.metas
+			m.catalogItemsList.ResetSelected()
+		} else {
+			if len(msg.metas) == 0 {
+				m.statusMsg = "No more items"
+				return m, nil
+			}
+			m.catalogMetas = append(m.catalogMetas, msg.metas...)
+		}
+		items := make([]list.Item, len(m.catalogMetas))
+		for i, meta := range m.catalogMetas {
+			items[i] = metaItem{meta: meta}
+		}
+		m.catalogItemsList.SetItems(items)
+		m.view = CatalogItemsView
 		m.errorMsg = ""
 		return m, nil
 
@@ -422,11 +913,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// No seasons = treat as movie, fetch streams directly
 			m.loading = true
 			m.loadingMsg = "Fetching streams..."
-			return m, tea.Batch(m.spinner.Tick, fetchStreams(m.selectedTitle.Id))
+			cmd := m.loadStreams(m.selectedTitle.StremioType(), m.selectedTitle.Id)
+			return m, tea.Batch(m.spinner.Tick, cmd)
 		}
 		items := make([]list.Item, len(msg.results))
 		for i, r := range msg.results {
-			items[i] = seasonItem{result: r}
+			items[i] = seasonItem{result: r, stale: msg.stale}
 		}
 		m.seasonsList.SetItems(items)
 		m.view = SeasonsView
@@ -443,7 +935,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		items := make([]list.Item, len(msg.results))
 		for i, r := range msg.results {
-			items[i] = episodeItem{result: r}
+			items[i] = episodeItem{result: r, stale: msg.stale}
 		}
 		m.allEpisodeItems = items // Store for filtering
 		m.episodesList.SetItems(items)
@@ -481,15 +973,41 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case mpvLaunchedMsg:
+	case torrentAddedMsg:
+		return m.torrentAdded(msg)
+
+	case torrentPollMsg:
+		cmd := m.pollTorrentProgress()
+		return m, cmd
+
+	case torrentProgressMsg:
+		m.torrentProgress(msg)
+		return m, pollTorrents()
+
+	case openedMsg:
+		if msg.err != nil {
+			m.errorMsg = "Failed to open link: " + msg.err.Error()
+		} else {
+			m.statusMsg = msg.status
+		}
+		return m, nil
+
+	case playerLaunchedMsg:
+		m.loading = false
 		if msg.err != nil {
-			m.errorMsg = "Failed to launch mpv: " + msg.err.Error()
+			m.errorMsg = "Failed to launch " + msg.player + ": " + msg.err.Error()
+		} else if msg.subtitles > 0 {
+			m.statusMsg = fmt.Sprintf("Playing in %s with %d subtitle track(s)...", msg.player, msg.subtitles)
 		} else {
-			m.statusMsg = "Playing in mpv..."
+			m.statusMsg = "Playing in " + msg.player + "..."
 		}
 		return m, nil
 
 	case batchStreamResultMsg:
+		// Ignore results of a batch that was cancelled
+		if m.batchFetching <= 0 {
+			return m, nil
+		}
 		m.batchFetching--
 
 		// Check for fetch error
@@ -505,6 +1023,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Find streams matching the release name and add them
 			found := false
 			for _, stream := range msg.streams {
+				// Only direct URLs, or torrents through debrid, can be
+				// downloaded in bulk
+				if kind := stream.Kind(); kind != apiutils.StreamURL &&
+					(kind != apiutils.StreamTorrent || m.debrid == nil) {
+					continue
+				}
 				if strings.Contains(strings.ToLower(stream.Name), releaseName) ||
 					strings.Contains(strings.ToLower(stream.BehaviorHints.Filename), releaseName) {
 					m.batchStreams = append(m.batchStreams, BatchStream{
@@ -544,8 +1068,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case addonManifestsMsg:
+		m.addonManifests = msg.addons
+		m.addonErrs = msg.errs
+		return m, nil
+
+	case addonCheckedMsg:
+		return m.addonChecked(msg)
+
 	case errorMsg:
 		m.loading = false
+		m.loadingMore = false
+		m.autoPick = ""
 		m.errorMsg = string(msg)
 		return m, nil
 	}
@@ -554,58 +1088,345 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) updateSearchView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Moving through the suggestions dropdown
+	switch msg.String() {
+	case "down":
+		if m.suggestIdx < len(m.suggestions)-1 {
+			m.suggestIdx++
+		}
+		return m, nil
+	case "up":
+		if m.suggestIdx >= 0 {
+			m.suggestIdx--
+		}
+		return m, nil
+	case "esc":
+		if len(m.suggestions) > 0 {
+			m.clearSuggestions()
+			return m, nil
+		}
+	case "enter":
+		if m.suggestIdx >= 0 && m.suggestIdx < len(m.suggestions) {
+			result := m.suggestions[m.suggestIdx]
+			m.clearSuggestions()
+			return m.openDetail(result, SearchView)
+		}
+	}
+
 	switch msg.String() {
 	case "enter":
 		query := strings.TrimSpace(m.searchInput.Value())
 		if query == "" {
 			return m, nil
 		}
+		m.clearSuggestions()
+
+		// Ids and links go straight to the title instead of searching
+		if link, ok := apiutils.ParseTitleLink(query); ok {
+			m.loading = true
+			m.loadingMsg = "Opening " + link.Id + "..."
+			m.errorMsg = ""
+			ctx := m.newRequest()
+			return m, tea.Batch(m.spinner.Tick, resolveTitleLink(ctx, m.api, link))
+		}
+
+		m.lastQuery = query
 		m.loading = true
 		m.loadingMsg = "Searching..."
 		m.errorMsg = ""
-		return m, tea.Batch(m.spinner.Tick, searchIMDB(query))
+		ctx := m.newRequest()
+		m.emptyPages = 0
+		return m, tea.Batch(m.spinner.Tick, searchIMDB(ctx, m.api, query, m.searchFilter, apiutils.SearchCursor{}))
+	case "ctrl+f":
+		m.clearSuggestions()
+		return m.openFilters()
+	case "ctrl+w":
+		m.clearSuggestions()
+		return m.showWatchlist()
+	case "ctrl+b":
+		// Browse addon catalogs instead of searching
+		m.clearSuggestions()
+		m.loading = true
+		m.loadingMsg = "Loading catalogs..."
+		m.errorMsg = ""
+		ctx := m.newRequest()
+		return m, tea.Batch(m.spinner.Tick, fetchCatalogs(ctx, m.api))
 	}
 
+	before := m.searchInput.Value()
 	var cmd tea.Cmd
 	m.searchInput, cmd = m.searchInput.Update(msg)
-	return m, cmd
+	if m.searchInput.Value() == before {
+		return m, cmd
+	}
+	suggest := m.suggest()
+	return m, tea.Batch(cmd, suggest)
+}
+
+// suggest schedules suggestions for the edited query. Each edit gets a new
+// sequence number, so the pending tick and any reply for an older query are
+// ignored.
+func (m *Model) suggest() tea.Cmd {
+	m.suggestSeq++
+	m.suggestIdx = -1
+	query := strings.TrimSpace(m.searchInput.Value())
+	if len([]rune(query)) < suggestMinLen {
+		m.suggestions = nil
+		return nil
+	}
+	return debounceSuggest(m.suggestSeq, query)
+}
+
+// clearSuggestions closes the dropdown and abandons pending lookups.
+func (m *Model) clearSuggestions() {
+	m.suggestSeq++
+	m.suggestions = nil
+	m.suggestIdx = -1
+	if m.cancelSuggest != nil {
+		m.cancelSuggest()
+		m.cancelSuggest = nil
+	}
 }
 
 func (m Model) updateResultsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
+		m.cancelRequests()
 		m.view = SearchView
 		m.searchInput.Focus()
 		return m, nil
+	case "f", "ctrl+f":
+		return m.openFilters()
+	case "n":
+		if !m.searchMore {
+			m.statusMsg = "No more results"
+			return m, nil
+		}
+		return m.loadMoreResults()
 	case "enter":
 		if item, ok := m.resultsList.SelectedItem().(imdbItem); ok {
-			m.selectedTitle = &item.result
-			m.selectedSeason = nil
-			m.selectedEpisode = nil
+			return m.openDetail(item.result, ResultsView)
+		}
+	case "P", "D":
+		if item, ok := m.resultsList.SelectedItem().(imdbItem); ok {
+			m.browseView = ResultsView
+			return m.autoPickTitle(item.result, msg.String())
+		}
+	}
+
+	var cmd tea.Cmd
+	m.resultsList, cmd = m.resultsList.Update(msg)
+
+	// Fetch the next page as the cursor nears the end of the list
+	if m.searchMore && m.resultsList.Index() >= len(m.resultsList.Items())-searchPrefetch {
+		var more tea.Cmd
+		m, more = m.loadMoreResults()
+		return m, tea.Batch(cmd, more)
+	}
+	return m, cmd
+}
+
+// searchPrefetch is how close to the last result the cursor gets before the
+// next page is fetched
+const searchPrefetch = 5
+
+// loadMoreResults fetches the next page of search results unless one is
+// already on its way.
+func (m Model) loadMoreResults() (Model, tea.Cmd) {
+	if m.loadingMore {
+		return m, nil
+	}
+	m.loadingMore = true
+	m.statusMsg = ""
+	ctx := m.newRequest()
+	return m, tea.Batch(m.spinner.Tick, searchIMDB(ctx, m.api, m.lastQuery, m.searchFilter, m.searchNext))
+}
+
+// selectTitle starts the seasons or streams flow for a title picked from
+// search results or a catalog.
+func (m Model) selectTitle(result apiutils.ImdbSearchResult) (tea.Model, tea.Cmd) {
+	m.selectedTitle = &result
+	m.selectedSeason = nil
+	m.selectedEpisode = nil
+	m.loading = true
+	m.errorMsg = ""
+
+	// Check if it's a series - fetch seasons first
+	if result.StremioType() == "series" {
+		m.loadingMsg = "Fetching seasons..."
+		ctx := m.newRequest()
+		return m, tea.Batch(m.spinner.Tick, fetchSeasons(ctx, m.api, result.Id))
+	}
+
+	// It's a movie - fetch streams directly
+	m.loadingMsg = "Fetching streams..."
+	cmd := m.loadStreams(result.StremioType(), result.Id)
+	return m, tea.Batch(m.spinner.Tick, cmd)
+}
+
+// refreshView fetches what the current view shows again, bypassing the
+// response cache.
+func (m Model) refreshView() (tea.Model, tea.Cmd) {
+	switch m.view {
+	case ResultsView, CatalogsView, CatalogItemsView, DetailView, SeasonsView, EpisodesView, StreamsView:
+	default:
+		return m, nil
+	}
+	if m.view == ResultsView && m.showingWatchlist {
+		return m, nil
+	}
+
+	ctx := apiutils.WithRefresh(m.newRequest())
+	var cmd tea.Cmd
+	switch m.view {
+	case ResultsView:
+		m.emptyPages = 0
+		cmd = searchIMDB(ctx, m.api, m.lastQuery, m.searchFilter, apiutils.SearchCursor{})
+	case CatalogsView:
+		cmd = fetchCatalogs(ctx, m.api)
+	case CatalogItemsView:
+		cmd = fetchCatalogItems(ctx, *m.selectedCatalog, 0, m.catalogGenre)
+	case DetailView:
+		m.detailLoading = true
+		cmd = fetchTitleDetails(ctx, m.api, *m.selectedTitle)
+	case SeasonsView:
+		cmd = fetchSeasons(ctx, m.api, m.selectedTitle.Id)
+	case EpisodesView:
+		cmd = fetchEpisodes(ctx, m.api, m.selectedTitle.Id, m.selectedSeason.Season)
+	case StreamsView:
+		m.subtitles = nil
+		cmd = tea.Batch(fetchStreams(ctx, m.api, m.streamsType, m.streamsId), fetchSubtitles(ctx, m.api, m.streamsType, m.streamsId))
+	}
+
+	m.loading = true
+	m.loadingMsg = "Refreshing..."
+	m.errorMsg = ""
+	m.statusMsg = ""
+	return m, tea.Batch(m.spinner.Tick, cmd)
+}
+
+// newRequest cancels whatever the previous view was still waiting for and
+// returns the context for the view's next request.
+func (m *Model) newRequest() context.Context {
+	m.cancelRequests()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelRequest = cancel
+	return ctx
+}
+
+// cancelRequests abandons the in-flight requests of the current view.
+func (m *Model) cancelRequests() {
+	if m.cancelRequest != nil {
+		m.cancelRequest()
+		m.cancelRequest = nil
+	}
+	m.loadingMore = false
+}
+
+// loadStreams remembers which title the streams belong to and fetches its
+// streams and subtitles together.
+func (m *Model) loadStreams(contentType, id string) tea.Cmd {
+	m.streamsType = contentType
+	m.streamsId = id
+	m.subtitles = nil
+	ctx := m.newRequest()
+	return tea.Batch(fetchStreams(ctx, m.api, contentType, id), fetchSubtitles(ctx, m.api, contentType, id))
+}
+
+func (m Model) updateCatalogsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.view = SearchView
+		m.searchInput.Focus()
+		return m, nil
+	case "enter":
+		if item, ok := m.catalogsList.SelectedItem().(catalogItem); ok {
+			ref := item.ref
+			m.selectedCatalog = &ref
+			m.catalogGenre = ""
+			// Some catalogs can only be listed by genre
+			for _, e := range ref.Catalog.Extra {
+				if e.Name == "genre" && e.IsRequired && len(e.Options) > 0 {
+					m.catalogGenre = e.Options[0]
+				}
+			}
 			m.loading = true
+			m.loadingMsg = "Loading catalog..."
 			m.errorMsg = ""
+			m.statusMsg = ""
+			ctx := m.newRequest()
+			return m, tea.Batch(m.spinner.Tick, fetchCatalogItems(ctx, ref, 0, m.catalogGenre))
+		}
+	}
 
-			// Check if it's a series - fetch seasons first
-			if item.result.Type == "tvSeries" || item.result.Type == "tvMiniSeries" {
-				m.loadingMsg = "Fetching seasons..."
-				return m, tea.Batch(m.spinner.Tick, fetchSeasons(item.result.Id))
-			}
+	var cmd tea.Cmd
+	m.catalogsList, cmd = m.catalogsList.Update(msg)
+	return m, cmd
+}
 
-			// It's a movie - fetch streams directly
-			m.loadingMsg = "Fetching streams..."
-			return m, tea.Batch(m.spinner.Tick, fetchStreams(item.result.Id))
+func (m Model) updateCatalogItemsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.view = CatalogsView
+		m.statusMsg = ""
+		return m, nil
+	case "n":
+		// Load the next page
+		m.loading = true
+		m.loadingMsg = "Loading more..."
+		m.statusMsg = ""
+		ctx := m.newRequest()
+		return m, tea.Batch(m.spinner.Tick, fetchCatalogItems(ctx, *m.selectedCatalog, len(m.catalogMetas), m.catalogGenre))
+	case "g":
+		// Cycle through the catalog's genres
+		genres := m.selectedCatalog.Genres()
+		if len(genres) == 0 {
+			return m, nil
+		}
+		next := 0
+		for i, g := range genres {
+			if g == m.catalogGenre {
+				next = i + 1
+			}
+		}
+		if next >= len(genres) {
+			m.catalogGenre = ""
+			// Genre-only catalogs can't be listed without one
+			for _, e := range m.selectedCatalog.Catalog.Extra {
+				if e.Name == "genre" && e.IsRequired {
+					m.catalogGenre = genres[0]
+				}
+			}
+		} else {
+			m.catalogGenre = genres[next]
+		}
+		m.loading = true
+		m.loadingMsg = "Loading catalog..."
+		m.statusMsg = ""
+		ctx := m.newRequest()
+		return m, tea.Batch(m.spinner.Tick, fetchCatalogItems(ctx, *m.selectedCatalog, 0, m.catalogGenre))
+	case "enter":
+		if item, ok := m.catalogItemsList.SelectedItem().(metaItem); ok {
+			m.statusMsg = ""
+			return m.openDetail(item.meta.AsSearchResult(), CatalogItemsView)
+		}
+	case "P", "D":
+		if item, ok := m.catalogItemsList.SelectedItem().(metaItem); ok {
+			m.browseView = CatalogItemsView
+			m.statusMsg = ""
+			return m.autoPickTitle(item.meta.AsSearchResult(), msg.String())
 		}
 	}
 
 	var cmd tea.Cmd
-	m.resultsList, cmd = m.resultsList.Update(msg)
+	m.catalogItemsList, cmd = m.catalogItemsList.Update(msg)
 	return m, cmd
 }
 
 func (m Model) updateSeasonsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
-		m.view = ResultsView
+		m.view = m.browseView
 		return m, nil
 	case "enter":
 		if item, ok := m.seasonsList.SelectedItem().(seasonItem); ok {
@@ -613,7 +1434,8 @@ func (m Model) updateSeasonsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.loading = true
 			m.loadingMsg = "Fetching episodes..."
 			m.errorMsg = ""
-			return m, tea.Batch(m.spinner.Tick, fetchEpisodes(m.selectedTitle.Id, item.result.Season))
+			ctx := m.newRequest()
+			return m, tea.Batch(m.spinner.Tick, fetchEpisodes(ctx, m.api, m.selectedTitle.Id, item.result.Season))
 		}
 	}
 
@@ -669,6 +1491,10 @@ func (m Model) updateEpisodesView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.filterInput.Focus()
 		return m, textinput.Blink
 	case "b":
+		if m.offline {
+			m.errorMsg = "Batch download is unavailable offline"
+			return m, nil
+		}
 		// Start batch download - enter release name
 		m.view = BatchInputView
 		m.batchInput.SetValue("")
@@ -688,8 +1514,14 @@ func (m Model) updateEpisodesView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.loadingMsg = "Fetching streams..."
 			m.errorMsg = ""
 			// For episodes, we need to use the episode ID with season:episode format
-			streamId := fmt.Sprintf("%s:%s:%d", m.selectedTitle.Id, m.selectedSeason.Season, item.result.EpisodeNumber)
-			return m, tea.Batch(m.spinner.Tick, fetchStreams(streamId))
+			streamId := item.result.StreamId(m.selectedTitle.Id)
+			cmd := m.loadStreams("series", streamId)
+			return m, tea.Batch(m.spinner.Tick, cmd)
+		}
+	case "P", "D":
+		if item, ok := m.episodesList.SelectedItem().(episodeItem); ok {
+			m.selectedEpisode = &item.result
+			return m.startAutoPick(autoPickAction(msg.String()), "series", item.result.StreamId(m.selectedTitle.Id))
 		}
 	}
 
@@ -717,13 +1549,13 @@ func (m Model) updateBatchInputView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.batchFailed = []BatchFailure{}
 		m.batchFetching = len(m.episodes)
 
-		// Start fetching streams for all episodes with staggered delays
+		// Start fetching streams for all episodes; the client's per-host
+		// limiter paces the requests
+		ctx := m.newRequest()
 		var cmds []tea.Cmd
 		cmds = append(cmds, m.spinner.Tick)
-		for i, ep := range m.episodes {
-			// Stagger requests by 5s each to avoid rate limiting
-			delay := time.Duration(i) * 5 * time.Second
-			cmds = append(cmds, fetchBatchStreams(m.selectedTitle.Id, m.selectedSeason.Season, ep, delay))
+		for _, ep := range m.episodes {
+			cmds = append(cmds, fetchBatchStreams(ctx, m.api, m.selectedTitle.Id, ep))
 		}
 		return m, tea.Batch(cmds...)
 	}
@@ -780,21 +1612,21 @@ func (m Model) updateBatchSelectView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			} else {
 				filename = sanitizeFilename(fmt.Sprintf("S%sE%02d_%s", m.selectedSeason.Season, bs.Episode.EpisodeNumber, bs.Stream.Name))
 			}
-			filepath := "downloads/" + filename
+			dest := filepath.Join(config.DOWNLOAD_DIR, filename)
 
 			// Add to downloads list
 			cancelChan := make(chan struct{})
 			download := Download{
 				ID:         m.nextDownloadID,
 				Name:       fmt.Sprintf("S%sE%02d: %s", m.selectedSeason.Season, bs.Episode.EpisodeNumber, bs.Stream.Name),
-				Filename:   filepath,
+				Filename:   dest,
 				URL:        bs.Stream.Url,
 				Progress:   0,
 				Status:     DownloadPending,
 				CancelChan: cancelChan,
 			}
 			m.downloads = append(m.downloads, download)
-			cmds = append(cmds, downloadStreamWithProgress(download.ID, bs.Stream.Url, filepath, cancelChan))
+			cmds = append(cmds, downloadStreamWithProgress(download.ID, m.debrid, bs.Stream, dest, cancelChan))
 			m.nextDownloadID++
 		}
 
@@ -810,6 +1642,30 @@ func (m Model) updateBatchSelectView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) updateStreamsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Handle subtitle language picker
+	if m.pickingSubs {
+		langs, _ := apiutils.SubtitleLanguages(m.subtitles)
+		switch msg.String() {
+		case "esc", "enter", "s":
+			m.pickingSubs = false
+		case "j", "down":
+			if m.subPickerIdx < len(langs)-1 {
+				m.subPickerIdx++
+			}
+		case "k", "up":
+			if m.subPickerIdx > 0 {
+				m.subPickerIdx--
+			}
+		case " ":
+			if m.subPickerIdx < len(langs) {
+				m.subLangs = toggleLang(m.subLangs, langs[m.subPickerIdx])
+			}
+		case "c":
+			m.subLangs = nil
+		}
+		return m, nil
+	}
+
 	// Handle filter mode
 	if m.isFiltering {
 		switch msg.String() {
@@ -829,20 +1685,7 @@ func (m Model) updateStreamsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			// Update filter input and filter items
 			var cmd tea.Cmd
 			m.filterInput, cmd = m.filterInput.Update(msg)
-
-			// Apply filter
-			filterText := strings.ToLower(m.filterInput.Value())
-			if filterText == "" {
-				m.streamsList.SetItems(m.allStreamItems)
-			} else {
-				var filtered []list.Item
-				for _, item := range m.allStreamItems {
-					if strings.Contains(strings.ToLower(item.FilterValue()), filterText) {
-						filtered = append(filtered, item)
-					}
-				}
-				m.streamsList.SetItems(filtered)
-			}
+			m.applyStreamFilter()
 			return m, cmd
 		}
 	}
@@ -860,50 +1703,66 @@ func (m Model) updateStreamsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.selectedEpisode != nil {
 			m.view = EpisodesView
 		} else {
-			m.view = ResultsView
+			m.view = m.browseView
 		}
+		// Stop subtitle lookups for the streams we're leaving
+		m.cancelRequests()
 		// Reset filter state for streams view
+		m.filterInput.SetValue("")
 		m.streamsList.SetItems(m.allStreamItems)
 		m.statusMsg = ""
 		m.errorMsg = ""
 		return m, nil
+	case "o":
+		// Cycle sort mode
+		m.streamSort = m.streamSort.Next()
+		m.streamSortDesc = m.streamSort.defaultDescending()
+		m.resortStreams()
+		return m, nil
+	case "O":
+		// Flip sort direction
+		if m.streamSort != SortNone {
+			m.streamSortDesc = !m.streamSortDesc
+			m.resortStreams()
+		}
+		return m, nil
+	case "Q":
+		// Cycle quality profile
+		if len(m.profiles) == 0 {
+			m.statusMsg = "No quality profiles configured"
+			return m, nil
+		}
+		m.profileIdx++
+		if m.profileIdx >= len(m.profiles) {
+			m.profileIdx = -1
+		}
+		m.scoreStreams()
+		m.resortStreams()
+		return m, nil
+	case "s":
+		// Pick subtitle languages
+		if len(m.subtitles) == 0 {
+			m.statusMsg = "No subtitles available"
+			return m, nil
+		}
+		m.pickingSubs = true
+		m.subPickerIdx = 0
+		return m, nil
+	case "p", "enter", "d":
+		if m.offline {
+			m.errorMsg = "Playing and downloading streams is unavailable offline"
+			return m, nil
+		}
+	}
+
+	switch msg.String() {
 	case "p", "enter":
 		if item, ok := m.streamsList.SelectedItem().(streamItem); ok {
-			m.selectedStream = &item.result
-			m.statusMsg = ""
-			m.errorMsg = ""
-			return m, playStream(item.result.Url)
+			return m.playStreamItem(item)
 		}
 	case "d":
 		if item, ok := m.streamsList.SelectedItem().(streamItem); ok {
-			// Use filename from API if available, otherwise sanitize the name
-			var filename string
-			if item.result.BehaviorHints.Filename != "" {
-				filename = item.result.BehaviorHints.Filename
-			} else {
-				filename = sanitizeFilename(item.result.Name)
-			}
-			filepath := "downloads/" + filename
-
-			// Add to downloads list
-			cancelChan := make(chan struct{})
-			download := Download{
-				ID:         m.nextDownloadID,
-				Name:       item.result.Name,
-				Filename:   filepath,
-				URL:        item.result.Url,
-				Progress:   0,
-				Status:     DownloadPending,
-				CancelChan: cancelChan,
-			}
-			m.downloads = append(m.downloads, download)
-			m.nextDownloadID++
-
-			m.statusMsg = "Download started - press Tab to view progress"
-			m.errorMsg = ""
-
-			// Start download in background with progress reporting
-			return m, downloadStreamWithProgress(download.ID, item.result.Url, filepath, cancelChan)
+			return m.downloadStreamItem(item)
 		}
 	}
 
@@ -912,6 +1771,99 @@ func (m Model) updateStreamsView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// playStreamItem plays a stream with the chosen subtitle languages.
+func (m Model) playStreamItem(item streamItem) (tea.Model, tea.Cmd) {
+	m.selectedStream = &item.result
+	m.statusMsg = ""
+	m.errorMsg = ""
+	switch item.result.Kind() {
+	case apiutils.StreamTorrent:
+		if m.debrid == nil && m.torrents != nil {
+			return m.handOffStream(item.result)
+		}
+		if m.debrid == nil {
+			break
+		}
+		if m.player == nil {
+			return m.noPlayer()
+		}
+		m.loading = true
+		m.loadingMsg = "Getting a link from " + m.debrid.Name() + "..."
+		ctx := m.newRequest()
+		return m, tea.Batch(m.spinner.Tick, playStream(ctx, m.api, m.debrid, m.player, m.streamsType, m.streamsId, m.mediaTitle(), item.result, m.subLangs, m.subtitles))
+	case apiutils.StreamURL, apiutils.StreamYouTube:
+		if m.player == nil {
+			return m.noPlayer()
+		}
+	}
+	return m, playStream(context.Background(), m.api, m.debrid, m.player, m.streamsType, m.streamsId, m.mediaTitle(), item.result, m.subLangs, m.subtitles)
+}
+
+// noPlayer explains why nothing can be played
+func (m Model) noPlayer() (tea.Model, tea.Cmd) {
+	m.errorMsg = "Player unavailable: " + m.playerErr.Error()
+	return m, nil
+}
+
+// mediaTitle names what's being played, for the player's window
+func (m Model) mediaTitle() string {
+	if m.selectedTitle == nil {
+		return ""
+	}
+	title := m.selectedTitle.PrimaryTitle
+	if m.selectedEpisode != nil {
+		title += fmt.Sprintf(" S%sE%02d", m.selectedEpisode.Season, m.selectedEpisode.EpisodeNumber)
+	}
+	return title
+}
+
+// downloadStreamItem adds a stream to the downloads, or hands torrents to the
+// torrent client when no debrid service is set up.
+func (m Model) downloadStreamItem(item streamItem) (tea.Model, tea.Cmd) {
+	switch item.result.Kind() {
+	case apiutils.StreamTorrent:
+		if m.debrid == nil && m.torrents != nil {
+			return m.handOffStream(item.result)
+		}
+		if m.debrid == nil {
+			m.errorMsg = ""
+			return m, openLink(item.result.MagnetURI(), "Sent magnet to torrent client")
+		}
+	case apiutils.StreamYouTube, apiutils.StreamExternal, apiutils.StreamUnknown:
+		m.errorMsg = "Only direct URL streams can be downloaded"
+		return m, nil
+	}
+
+	// Use filename from API if available, otherwise sanitize the name
+	var filename string
+	if item.result.BehaviorHints.Filename != "" {
+		filename = item.result.BehaviorHints.Filename
+	} else {
+		filename = sanitizeFilename(item.result.Name)
+	}
+	dest := filepath.Join(config.DOWNLOAD_DIR, filename)
+
+	// Add to downloads list
+	cancelChan := make(chan struct{})
+	download := Download{
+		ID:         m.nextDownloadID,
+		Name:       item.result.Name,
+		Filename:   dest,
+		URL:        item.result.Url,
+		Progress:   0,
+		Status:     DownloadPending,
+		CancelChan: cancelChan,
+	}
+	m.downloads = append(m.downloads, download)
+	m.nextDownloadID++
+
+	m.statusMsg = "Download started - press Tab to view progress"
+	m.errorMsg = ""
+
+	// Start download in background with progress reporting
+	return m, downloadStreamWithProgress(download.ID, m.debrid, item.result, dest, cancelChan)
+}
+
 func (m Model) updateDownloadsTab(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
@@ -927,6 +1879,18 @@ func (m Model) updateDownloadsTab(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.selectedDownloadIdx--
 		}
 		return m, nil
+	case "p", "enter":
+		// Play a finished download from disk
+		if len(m.downloads) > 0 && m.selectedDownloadIdx < len(m.downloads) {
+			d := m.downloads[m.selectedDownloadIdx]
+			if d.Status == DownloadComplete && d.Filename != "" {
+				if m.player == nil {
+					return m.noPlayer()
+				}
+				return m, playFile(m.player, d.Filename)
+			}
+		}
+		return m, nil
 	case "x":
 		// Cancel selected download
 		if len(m.downloads) > 0 && m.selectedDownloadIdx < len(m.downloads) {
@@ -949,18 +1913,28 @@ func (m Model) View() string {
 	// Show downloads tab or main content
 	if m.currentTab == DownloadsTab {
 		content = m.downloadsPageView()
+	} else if m.currentTab == AddonsTab {
+		content = m.addonsPageView()
+	} else if m.editingFilters {
+		content = m.renderFilterEditor()
 	} else {
 		switch m.view {
 		case SearchView:
 			content = m.searchView()
 		case ResultsView:
 			content = m.resultsView()
+		case CatalogsView:
+			content = m.catalogsView()
+		case CatalogItemsView:
+			content = m.catalogItemsView()
 		case SeasonsView:
 			content = m.seasonsView()
 		case EpisodesView:
 			content = m.episodesView()
 		case StreamsView:
 			content = m.streamsView()
+		case DetailView:
+			content = m.detailView()
 		case BatchInputView:
 			content = m.batchInputView()
 		case BatchSelectView:
@@ -974,6 +1948,65 @@ func (m Model) View() string {
 	return content + "\n" + tabBar
 }
 
+// resortStreams orders all streams by the current sort mode and re-applies
+// the filter, so the full and filtered sets stay in the same order
+func (m *Model) resortStreams() {
+	sortStreamItems(m.allStreamItems, m.streamSort, m.streamSortDesc)
+	pinBest(m.allStreamItems)
+
+	title := "Available Streams"
+	if m.streamSort != SortNone {
+		arrow := "↑"
+		if m.streamSortDesc {
+			arrow = "↓"
+		}
+		title += " • by " + m.streamSort.String() + " " + arrow
+	}
+	if p := m.profile(); p != nil {
+		title += " • profile: " + p.Name
+	}
+	m.streamsList.Title = title
+	m.applyStreamFilter()
+}
+
+// applyStreamFilter shows the streams matching the filter input
+func (m *Model) applyStreamFilter() {
+	filterText := strings.ToLower(m.filterInput.Value())
+	if filterText == "" {
+		m.streamsList.SetItems(m.allStreamItems)
+		return
+	}
+	var filtered []list.Item
+	for _, item := range m.allStreamItems {
+		if strings.Contains(strings.ToLower(item.FilterValue()), filterText) {
+			filtered = append(filtered, item)
+		}
+	}
+	m.streamsList.SetItems(filtered)
+}
+
+// existingDownloads lists the files already in dir as completed downloads
+func existingDownloads(dir string) []Download {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return []Download{}
+	}
+	downloads := []Download{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		downloads = append(downloads, Download{
+			ID:       len(downloads),
+			Name:     e.Name(),
+			Filename: filepath.Join(dir, e.Name()),
+			Progress: 1.0,
+			Status:   DownloadComplete,
+		})
+	}
+	return downloads
+}
+
 // Helper to count active downloads
 func (m Model) activeDownloadCount() int {
 	count := 0
@@ -1003,6 +2036,40 @@ func sanitizeFilename(name string) string {
 	return result + ".mp4"
 }
 
+// staleSuffix marks list descriptions that come from an expired cache entry
+func staleSuffix(desc string, stale bool) string {
+	if stale {
+		return desc + " (stale)"
+	}
+	return desc
+}
+
+// toggleLang adds lang to the chosen languages or removes it if present
+func toggleLang(langs []string, lang string) []string {
+	for i, l := range langs {
+		if l == lang {
+			return append(langs[:i:i], langs[i+1:]...)
+		}
+	}
+	return append(langs, lang)
+}
+
+// typeLabel returns a display label for an addon content type
+func typeLabel(contentType string) string {
+	switch contentType {
+	case "movie":
+		return "Movie"
+	case "series":
+		return "TV"
+	case "channel":
+		return "Channel"
+	case "tv":
+		return "Live TV"
+	default:
+		return contentType
+	}
+}
+
 func formatSize(bytes int64) string {
 	const (
 		KB = 1024
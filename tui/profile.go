@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rshero/stremio-tui/quality"
+	apiutils "github.com/rshero/stremio-tui/utils"
+)
+
+// profile returns the active quality profile, or nil.
+func (m Model) profile() *quality.Profile {
+	if m.profileIdx < 0 || m.profileIdx >= len(m.profiles) {
+		return nil
+	}
+	return &m.profiles[m.profileIdx]
+}
+
+// scoreStreams rates every stream with the active profile and marks the
+// highest scoring one that passes its rules. Ties go to the stream the
+// addons listed first.
+func (m *Model) scoreStreams() {
+	p := m.profile()
+	best := -1
+	for i, it := range m.allStreamItems {
+		item := it.(streamItem)
+		item.score, item.rejected, item.best = 0, false, false
+		if p != nil {
+			score, ok := p.Score(item.info, item.text())
+			item.score, item.rejected = score, !ok
+			if ok && (best < 0 || betterMatch(item, m.allStreamItems[best].(streamItem))) {
+				best = i
+			}
+		}
+		m.allStreamItems[i] = item
+	}
+	if best >= 0 {
+		item := m.allStreamItems[best].(streamItem)
+		item.best = true
+		m.allStreamItems[best] = item
+	}
+}
+
+func betterMatch(a, b streamItem) bool {
+	if a.score != b.score {
+		return a.score > b.score
+	}
+	return a.order < b.order
+}
+
+// pinBest moves the best match to the top of the list.
+func pinBest(items []list.Item) {
+	for i, it := range items {
+		if it.(streamItem).best {
+			copy(items[1:i+1], items[:i])
+			items[0] = it
+			return
+		}
+	}
+}
+
+// bestStream returns the stream marked as the best match.
+func (m Model) bestStream() (streamItem, bool) {
+	for _, it := range m.allStreamItems {
+		if item := it.(streamItem); item.best {
+			return item, true
+		}
+	}
+	return streamItem{}, false
+}
+
+// autoPickAction maps the P and D keys to what is done with the best match.
+func autoPickAction(key string) string {
+	if key == "D" {
+		return "download"
+	}
+	return "play"
+}
+
+// autoPickTitle plays or downloads the best stream of a movie picked from
+// search results or a catalog. Series need an episode first.
+func (m Model) autoPickTitle(result apiutils.ImdbSearchResult, key string) (tea.Model, tea.Cmd) {
+	if result.StremioType() == "series" {
+		m.errorMsg = "Open the series and pick an episode first"
+		return m, nil
+	}
+	m.selectedTitle = &result
+	m.selectedSeason = nil
+	m.selectedEpisode = nil
+	return m.startAutoPick(autoPickAction(key), result.StremioType(), result.Id)
+}
+
+// startAutoPick fetches the streams without opening the streams list; once
+// they arrive the best match is played or downloaded.
+func (m Model) startAutoPick(action, contentType, id string) (tea.Model, tea.Cmd) {
+	if m.offline {
+		m.errorMsg = "Playing and downloading streams is unavailable offline"
+		return m, nil
+	}
+	if m.profile() == nil {
+		m.errorMsg = "No quality profile selected (set QUALITY_PROFILE)"
+		return m, nil
+	}
+	m.autoPick = action
+	m.loading = true
+	m.loadingMsg = "Finding the best stream..."
+	m.errorMsg = ""
+	m.statusMsg = ""
+	cmd := m.loadStreams(contentType, id)
+	return m, tea.Batch(m.spinner.Tick, cmd)
+}
+
+// pickBest acts on the best match of the streams just fetched. The streams
+// list stays closed; enter on the title opens it as usual.
+func (m Model) pickBest(action string) (tea.Model, tea.Cmd) {
+	best, ok := m.bestStream()
+	if !ok {
+		m.errorMsg = fmt.Sprintf("No stream matches the %q profile", m.profile().Name)
+		return m, nil
+	}
+	if action == "download" {
+		return m.downloadStreamItem(best)
+	}
+	return m.playStreamItem(best)
+}
@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+
+	"github.com/rshero/stremio-tui/quality"
+	apiutils "github.com/rshero/stremio-tui/utils"
+)
+
+func TestScoreStreamsPicksBest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	data := `{"profiles": [{"name": "1080p",
+		"resolutions": {"preferred": ["1080p", "720p"], "forbidden": ["480p"]},
+		"keywords": {"forbidden": ["CAM"]}}]}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	profiles, err := quality.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		releases []string
+		best     int // index of the best match, -1 for none
+	}{
+		{"preferred wins", []string{"Movie.720p.WEB-DL", "Movie.1080p.WEB-DL", "Movie.2160p.WEB-DL"}, 1},
+		{"tie goes to the first listed", []string{"Movie.1080p.WEB-DL-A", "Movie.1080p.WEB-DL-B"}, 0},
+		{"rejected never wins", []string{"Movie.480p.DVDRip", "Movie.1080p.CAM", "Movie.WEB-DL"}, 2},
+		{"all rejected", []string{"Movie.480p.DVDRip", "Movie.1080p.CAM"}, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Model{profiles: profiles}
+			for i, release := range tt.releases {
+				m.allStreamItems = append(m.allStreamItems, newStreamItem(apiutils.AlcSearchResult{Name: release}, i, false))
+			}
+			m.scoreStreams()
+
+			best, ok := m.bestStream()
+			if tt.best < 0 {
+				if ok {
+					t.Errorf("best = %q, want none", best.result.Name)
+				}
+				return
+			}
+			if !ok || best.order != tt.best {
+				t.Fatalf("best = %q (%v), want %q", best.result.Name, ok, tt.releases[tt.best])
+			}
+
+			items := append([]list.Item{}, m.allStreamItems...)
+			pinBest(items)
+			if items[0].(streamItem).order != tt.best {
+				t.Errorf("pinned %q, want %q", items[0].(streamItem).result.Name, tt.releases[tt.best])
+			}
+		})
+	}
+}
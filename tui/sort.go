@@ -0,0 +1,118 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// StreamSort is how the streams list is ordered
+type StreamSort int
+
+const (
+	SortNone StreamSort = iota // addon order as returned
+	SortResolution
+	SortSize
+	SortSeeders
+	SortCodec
+	SortAddon
+	SortScore // quality profile score
+)
+
+func (s StreamSort) String() string {
+	switch s {
+	case SortResolution:
+		return "resolution"
+	case SortSize:
+		return "size"
+	case SortSeeders:
+		return "seeders"
+	case SortCodec:
+		return "codec"
+	case SortAddon:
+		return "addon"
+	case SortScore:
+		return "profile score"
+	default:
+		return "addon order"
+	}
+}
+
+// Next cycles to the following sort mode
+func (s StreamSort) Next() StreamSort {
+	if s == SortScore {
+		return SortNone
+	}
+	return s + 1
+}
+
+// defaultDescending puts the best streams first for numeric modes and
+// alphabetical order for the addon mode
+func (s StreamSort) defaultDescending() bool {
+	return s != SortAddon
+}
+
+// codecRank orders video codecs from most to least efficient
+var codecRank = map[string]int{
+	"AV1":  5,
+	"HEVC": 4,
+	"AVC":  3,
+	"VP9":  2,
+	"XviD": 1,
+}
+
+// compareStreams returns <0, 0 or >0 like strings.Compare, in ascending order
+func compareStreams(mode StreamSort, a, b streamItem) int {
+	switch mode {
+	case SortResolution:
+		return a.info.Height - b.info.Height
+	case SortSize:
+		return compareInt64(a.info.Size, b.info.Size)
+	case SortSeeders:
+		return a.info.Seeders - b.info.Seeders
+	case SortCodec:
+		return codecRank[a.info.VideoCodec] - codecRank[b.info.VideoCodec]
+	case SortAddon:
+		return strings.Compare(strings.ToLower(a.result.Addon), strings.ToLower(b.result.Addon))
+	case SortScore:
+		return a.profileRank() - b.profileRank()
+	default:
+		return 0
+	}
+}
+
+// profileRank puts streams the profile excluded below every other stream
+func (i streamItem) profileRank() int {
+	if i.rejected {
+		return -1
+	}
+	return i.score
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// sortStreamItems orders stream items in place. Ties, and SortNone, keep the
+// order the addons returned them in.
+func sortStreamItems(items []list.Item, mode StreamSort, descending bool) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].(streamItem), items[j].(streamItem)
+		c := compareStreams(mode, a, b)
+		if c == 0 {
+			return a.order < b.order
+		}
+		if descending {
+			return c > 0
+		}
+		return c < 0
+	})
+}
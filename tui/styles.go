@@ -1,116 +1,162 @@
 package tui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/charmbracelet/lipgloss"
+
+	config "github.com/rshero/stremio-tui/config"
+)
+
+// Colors, set from the theme
+var (
+	primaryColor   lipgloss.Color
+	secondaryColor lipgloss.Color
+	accentColor    lipgloss.Color
+	errorColor     lipgloss.Color
+	mutedColor     lipgloss.Color
+)
 
 var (
-	// Colors
-	primaryColor   = lipgloss.Color("#7C3AED")
-	secondaryColor = lipgloss.Color("#A78BFA")
-	accentColor    = lipgloss.Color("#10B981")
-	errorColor     = lipgloss.Color("#EF4444")
-	mutedColor     = lipgloss.Color("#6B7280")
-	bgColor        = lipgloss.Color("#1F2937")
+	TitleStyle            lipgloss.Style
+	SubtitleStyle         lipgloss.Style
+	InputStyle            lipgloss.Style
+	SelectedStyle         lipgloss.Style
+	NormalStyle           lipgloss.Style
+	DimStyle              lipgloss.Style
+	ErrorStyle            lipgloss.Style
+	SuccessStyle          lipgloss.Style
+	SpinnerStyle          lipgloss.Style
+	HelpStyle             lipgloss.Style
+	StatusStyle           lipgloss.Style
+	ChipStyle             lipgloss.Style
+	ProgressBarStyle      lipgloss.Style
+	BoxStyle              lipgloss.Style
+	TabActiveStyle        lipgloss.Style
+	TabInactiveStyle      lipgloss.Style
+	DownloadItemStyle     lipgloss.Style
+	DownloadActiveStyle   lipgloss.Style
+	DownloadCompleteStyle lipgloss.Style
+	DownloadFailedStyle   lipgloss.Style
+)
+
+func init() {
+	SetTheme(config.THEME)
+}
+
+// SetTheme rebuilds every style with the theme's colors. Lists created
+// earlier keep the styles they were given.
+func SetTheme(theme config.Theme) {
+	primaryColor = lipgloss.Color(theme.Primary)
+	secondaryColor = lipgloss.Color(theme.Secondary)
+	accentColor = lipgloss.Color(theme.Accent)
+	errorColor = lipgloss.Color(theme.Error)
+	mutedColor = lipgloss.Color(theme.Muted)
 
 	// Title style
 	TitleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(primaryColor).
-			MarginBottom(1)
+		Bold(true).
+		Foreground(primaryColor).
+		MarginBottom(1)
 
 	// Subtitle/help text
 	SubtitleStyle = lipgloss.NewStyle().
-			Foreground(mutedColor).
-			MarginBottom(1)
+		Foreground(mutedColor).
+		MarginBottom(1)
 
 	// Input field style
 	InputStyle = lipgloss.NewStyle().
-			BorderStyle(lipgloss.RoundedBorder()).
-			BorderForeground(primaryColor).
-			Padding(0, 1)
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(primaryColor).
+		Padding(0, 1)
 
 	// Selected item in list
 	SelectedStyle = lipgloss.NewStyle().
-			Foreground(accentColor).
-			Bold(true)
+		Foreground(accentColor).
+		Bold(true)
 
 	// Normal item in list
 	NormalStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#E5E7EB"))
+		Foreground(lipgloss.Color("#E5E7EB"))
 
 	// Dimmed/description text
 	DimStyle = lipgloss.NewStyle().
-			Foreground(mutedColor)
+		Foreground(mutedColor)
 
 	// Error style
 	ErrorStyle = lipgloss.NewStyle().
-			Foreground(errorColor).
-			Bold(true)
+		Foreground(errorColor).
+		Bold(true)
 
 	// Success style
 	SuccessStyle = lipgloss.NewStyle().
-			Foreground(accentColor).
-			Bold(true)
+		Foreground(accentColor).
+		Bold(true)
 
 	// Loading/spinner style
 	SpinnerStyle = lipgloss.NewStyle().
-			Foreground(secondaryColor)
+		Foreground(secondaryColor)
 
 	// Help bar at bottom
 	HelpStyle = lipgloss.NewStyle().
-			Foreground(mutedColor).
-			MarginTop(1)
+		Foreground(mutedColor).
+		MarginTop(1)
 
 	// Status message style
 	StatusStyle = lipgloss.NewStyle().
-			Foreground(secondaryColor).
-			Italic(true)
+		Foreground(secondaryColor).
+		Italic(true)
+
+	// Active filter chip
+	ChipStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#E5E7EB")).
+		Background(primaryColor).
+		Padding(0, 1)
 
 	// Progress bar styles
 	ProgressBarStyle = lipgloss.NewStyle().
-				Foreground(accentColor)
+		Foreground(accentColor)
 
 	// Box for content
 	BoxStyle = lipgloss.NewStyle().
-			BorderStyle(lipgloss.RoundedBorder()).
-			BorderForeground(mutedColor).
-			Padding(1, 2)
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(mutedColor).
+		Padding(1, 2)
 
 	// Tab styles
 	TabActiveStyle = lipgloss.NewStyle().
-			BorderStyle(lipgloss.RoundedBorder()).
-			BorderForeground(primaryColor).
-			Foreground(primaryColor).
-			Bold(true).
-			Padding(0, 2)
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(primaryColor).
+		Foreground(primaryColor).
+		Bold(true).
+		Padding(0, 2)
 
 	TabInactiveStyle = lipgloss.NewStyle().
-				BorderStyle(lipgloss.RoundedBorder()).
-				BorderForeground(mutedColor).
-				Foreground(mutedColor).
-				Padding(0, 2)
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(mutedColor).
+		Foreground(mutedColor).
+		Padding(0, 2)
 
 	// Download item styles
 	DownloadItemStyle = lipgloss.NewStyle().
-				BorderStyle(lipgloss.RoundedBorder()).
-				BorderForeground(mutedColor).
-				Padding(0, 1).
-				MarginBottom(1)
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(mutedColor).
+		Padding(0, 1).
+		MarginBottom(1)
 
 	DownloadActiveStyle = lipgloss.NewStyle().
-				BorderStyle(lipgloss.RoundedBorder()).
-				BorderForeground(accentColor).
-				Padding(0, 1).
-				MarginBottom(1)
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(accentColor).
+		Padding(0, 1).
+		MarginBottom(1)
 
 	DownloadCompleteStyle = lipgloss.NewStyle().
-				BorderStyle(lipgloss.RoundedBorder()).
-				BorderForeground(accentColor).
-				Padding(0, 1).
-				MarginBottom(1)
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(accentColor).
+		Padding(0, 1).
+		MarginBottom(1)
 
 	DownloadFailedStyle = lipgloss.NewStyle().
-				BorderStyle(lipgloss.RoundedBorder()).
-				BorderForeground(errorColor).
-				Padding(0, 1).
-				MarginBottom(1)
-)
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(errorColor).
+		Padding(0, 1).
+		MarginBottom(1)
+}
@@ -0,0 +1,105 @@
+package tui
+
+import (
+	"errors"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	apiutils "github.com/rshero/stremio-tui/utils"
+)
+
+// handOffStream sends a torrent stream to the torrent client and lists it in
+// the downloads.
+func (m Model) handOffStream(stream apiutils.AlcSearchResult) (tea.Model, tea.Cmd) {
+	name := stream.BehaviorHints.Filename
+	if name == "" {
+		name = stream.Name
+	}
+	download := Download{
+		ID:     m.nextDownloadID,
+		Name:   name,
+		Status: DownloadPending,
+		Client: m.torrents.Name(),
+	}
+	m.downloads = append(m.downloads, download)
+	m.nextDownloadID++
+
+	m.statusMsg = "Sending to " + download.Client + " - press Tab to view"
+	m.errorMsg = ""
+	return m, handOffTorrent(m.torrents, download.ID, stream)
+}
+
+// torrentAdded records the client's id for a handed-off torrent and starts
+// polling when the client can report progress.
+func (m Model) torrentAdded(msg torrentAddedMsg) (tea.Model, tea.Cmd) {
+	for i := range m.downloads {
+		d := &m.downloads[i]
+		if d.ID != msg.id {
+			continue
+		}
+		switch {
+		case d.Status == DownloadCancelled:
+		case msg.err != nil:
+			d.Status = DownloadFailed
+			d.Error = msg.err
+		case msg.torrentId == "":
+			d.Status = DownloadHandedOff
+		default:
+			d.Status = DownloadInProgress
+			d.Torrent = msg.torrentId
+		}
+		break
+	}
+
+	if m.pollingTorrents || len(m.polledTorrents()) == 0 {
+		return m, nil
+	}
+	m.pollingTorrents = true
+	return m, pollTorrents()
+}
+
+// polledTorrents returns the client ids of the torrents still downloading
+func (m Model) polledTorrents() []string {
+	var ids []string
+	for _, d := range m.downloads {
+		if d.Torrent != "" && d.Status == DownloadInProgress {
+			ids = append(ids, d.Torrent)
+		}
+	}
+	return ids
+}
+
+// pollTorrentProgress asks the client about the torrents still downloading.
+// Polling stops once there are none; torrentAdded restarts it.
+func (m *Model) pollTorrentProgress() tea.Cmd {
+	ids := m.polledTorrents()
+	if len(ids) == 0 {
+		m.pollingTorrents = false
+		return nil
+	}
+	return fetchTorrentProgress(m.torrents, ids)
+}
+
+// torrentProgress updates the handed-off downloads. A failed poll is
+// skipped, as the client may just be restarting.
+func (m *Model) torrentProgress(msg torrentProgressMsg) {
+	if msg.err != nil {
+		return
+	}
+	for i := range m.downloads {
+		d := &m.downloads[i]
+		status, ok := msg.statuses[d.Torrent]
+		if d.Torrent == "" || d.Status != DownloadInProgress || !ok {
+			continue
+		}
+		d.Progress = status.Progress
+		switch {
+		case status.Error != "":
+			d.Status = DownloadFailed
+			d.Error = errors.New(status.Error)
+		case status.Done:
+			d.Progress = 1.0
+			d.Status = DownloadComplete
+		}
+	}
+}
@@ -5,8 +5,21 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+
+	config "github.com/rshero/stremio-tui/config"
+	apiutils "github.com/rshero/stremio-tui/utils"
 )
 
+// loadingLine renders the spinner and loading message, plus the rate limit
+// backoff when a host has asked us to slow down.
+func (m Model) loadingLine() string {
+	line := m.spinner.View() + " " + m.loadingMsg
+	if host, remaining, ok := m.api.Backoff(); ok {
+		line += DimStyle.Render(fmt.Sprintf(" (rate limited by %s, retrying in %ds)", host, int(remaining.Seconds())+1))
+	}
+	return line
+}
+
 func (m Model) searchView() string {
 	var b strings.Builder
 
@@ -14,18 +27,25 @@ func (m Model) searchView() string {
 	b.WriteString(title + "\n\n")
 
 	if m.loading {
-		b.WriteString(m.spinner.View() + " " + m.loadingMsg + "\n\n")
+		b.WriteString(m.loadingLine() + "\n\n")
 	} else {
 		b.WriteString(SubtitleStyle.Render("Search for movies and TV shows") + "\n\n")
 	}
 
-	b.WriteString(InputStyle.Render(m.searchInput.View()) + "\n\n")
+	b.WriteString(InputStyle.Render(m.searchInput.View()) + "\n")
+	b.WriteString(m.renderSuggestions())
+	b.WriteString(m.renderFilterChips() + "\n")
 
 	if m.errorMsg != "" {
 		b.WriteString(ErrorStyle.Render(m.errorMsg) + "\n\n")
 	}
 
-	help := HelpStyle.Render("enter: search • ctrl+c: quit")
+	quit := keyFor(config.ScopeSearch, "quit") + ": quit"
+	help := HelpStyle.Render("enter: search • " + keyFor(config.ScopeSearch, "search_filters") + ": filters • " +
+		keyFor(config.ScopeSearch, "show_watchlist") + ": watchlist • " + keyFor(config.ScopeSearch, "browse") + ": browse catalogs • " + quit)
+	if len(m.suggestions) > 0 {
+		help = HelpStyle.Render("↑/↓: suggestions • enter: open/search • esc: close • " + quit)
+	}
 	b.WriteString(help)
 
 	return lipgloss.Place(
@@ -39,7 +59,7 @@ func (m Model) resultsView() string {
 	var b strings.Builder
 
 	if m.loading {
-		loading := m.spinner.View() + " " + m.loadingMsg
+		loading := m.loadingLine()
 		return lipgloss.Place(
 			m.width, m.height,
 			lipgloss.Center, lipgloss.Center,
@@ -47,24 +67,209 @@ func (m Model) resultsView() string {
 		)
 	}
 
+	// Show the query and how many results have been loaded
+	info := fmt.Sprintf("\"%s\" • %d loaded", m.lastQuery, len(m.imdbResults))
+	if m.showingWatchlist {
+		info = fmt.Sprintf("Watchlist • %d titles", len(m.imdbResults))
+	} else if m.loadingMore {
+		info += " • " + m.spinner.View() + " loading more..."
+	} else if m.searchMore {
+		info += " • more available"
+	}
+	b.WriteString(DimStyle.Render(info) + "\n")
+	b.WriteString(m.renderFilterChips())
+
 	b.WriteString(m.resultsList.View())
 	b.WriteString("\n")
 
+	if m.statusMsg != "" {
+		b.WriteString(StatusStyle.Render(m.statusMsg) + "\n")
+	}
+
+	if m.errorMsg != "" {
+		b.WriteString(ErrorStyle.Render(m.errorMsg) + "\n")
+	}
+
+	help := HelpStyle.Render("enter: select • " + bestKeys(config.ScopeResults) + ": play/download best match • " +
+		keyFor(config.ScopeResults, "load_more") + ": load more • " + keyFor(config.ScopeResults, "search_filters") + ": filters • esc: back • " +
+		keyFor(config.ScopeResults, "quit") + ": quit")
+	b.WriteString(help)
+
+	return b.String()
+}
+
+// renderSuggestions draws the dropdown of titles below the search input
+func (m Model) renderSuggestions() string {
+	var b strings.Builder
+	for i, r := range m.suggestions {
+		label := DimStyle.Render(" • " + imdbItem{result: r}.typeLabel())
+		if i == m.suggestIdx {
+			b.WriteString(SelectedStyle.Render("  › "+r.PrimaryTitle) + label + "\n")
+		} else {
+			b.WriteString(NormalStyle.Render("    "+r.PrimaryTitle) + label + "\n")
+		}
+	}
+	return b.String()
+}
+
+func (m Model) detailView() string {
+	var b strings.Builder
+
+	if m.loading {
+		loading := m.loadingLine()
+		return lipgloss.Place(
+			m.width, m.height,
+			lipgloss.Center, lipgloss.Center,
+			loading,
+		)
+	}
+
+	t := m.selectedTitle
+	width := min(m.width-8, 80)
+
+	b.WriteString(TitleStyle.Render(t.PrimaryTitle) + "\n")
+	if t.OriginalTitle != "" && t.OriginalTitle != t.PrimaryTitle {
+		b.WriteString(DimStyle.Render(t.OriginalTitle) + "\n")
+	}
+
+	// Type, years and runtime on one line
+	facts := []string{imdbItem{result: *t}.typeLabel()}
+	if years := t.Years(); years != "" {
+		facts = append(facts, years)
+	}
+	if runtime := t.Runtime(); runtime != "" {
+		facts = append(facts, runtime)
+	}
+	b.WriteString(NormalStyle.Render(strings.Join(facts, " • ")) + "\n")
+
+	if r := t.Rating; r != nil && r.AggregateRating > 0 {
+		rating := fmt.Sprintf("★ %.1f", r.AggregateRating)
+		if r.VoteCount > 0 {
+			rating += DimStyle.Render(" (" + formatVotes(r.VoteCount) + " votes)")
+		}
+		b.WriteString(SelectedStyle.Render(rating) + "\n")
+	}
+	if len(t.Genres) > 0 {
+		b.WriteString(StatusStyle.Render(strings.Join(t.Genres, " • ")) + "\n")
+	}
+	b.WriteString("\n")
+
+	switch {
+	case t.Plot != "":
+		b.WriteString(lipgloss.NewStyle().Width(width).Render(t.Plot) + "\n")
+	case m.detailLoading:
+		b.WriteString(m.spinner.View() + DimStyle.Render(" Loading details...") + "\n")
+	default:
+		b.WriteString(DimStyle.Render("No plot available") + "\n")
+	}
+
+	if m.watchlist != nil && m.watchlist.Has(t.Id) {
+		b.WriteString("\n" + SuccessStyle.Render("✓ On your watchlist") + "\n")
+	}
+
+	if m.statusMsg != "" {
+		b.WriteString("\n" + StatusStyle.Render(m.statusMsg) + "\n")
+	}
+
+	if m.errorMsg != "" {
+		b.WriteString("\n" + ErrorStyle.Render(m.errorMsg) + "\n")
+	}
+
+	more := keyFor(config.ScopeDetail, "trailer") + ": trailer • " + keyFor(config.ScopeDetail, "watchlist") + ": watchlist • esc: back"
+	help := "enter/s: streams • " + bestKeys(config.ScopeDetail) + ": play/download best • " + more
+	if t.StremioType() == "series" {
+		help = "enter/" + keyFor(config.ScopeDetail, "episodes") + ": episodes • " + more
+	}
+	b.WriteString(HelpStyle.Render(help))
+
+	return lipgloss.NewStyle().Padding(1, 2).Render(b.String())
+}
+
+// formatVotes shortens vote counts: 2134567 -> 2.1M
+func formatVotes(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.0fK", float64(n)/1_000)
+	default:
+		return fmt.Sprint(n)
+	}
+}
+
+func (m Model) catalogsView() string {
+	var b strings.Builder
+
+	if m.loading {
+		loading := m.loadingLine()
+		return lipgloss.Place(
+			m.width, m.height,
+			lipgloss.Center, lipgloss.Center,
+			loading,
+		)
+	}
+
+	b.WriteString(m.catalogsList.View())
+	b.WriteString("\n")
+
 	if m.errorMsg != "" {
 		b.WriteString(ErrorStyle.Render(m.errorMsg) + "\n")
 	}
 
-	help := HelpStyle.Render("enter: select • esc: back • j/k: navigate • q: quit")
+	help := HelpStyle.Render("enter: open • esc: back • j/k: navigate • " + keyFor(config.ScopeCatalogs, "quit") + ": quit")
 	b.WriteString(help)
 
 	return b.String()
 }
 
+func (m Model) catalogItemsView() string {
+	var b strings.Builder
+
+	if m.loading {
+		loading := m.loadingLine()
+		return lipgloss.Place(
+			m.width, m.height,
+			lipgloss.Center, lipgloss.Center,
+			loading,
+		)
+	}
+
+	// Show catalog, genre and how much has been loaded
+	if m.selectedCatalog != nil {
+		info := fmt.Sprintf("%s (%s)", m.selectedCatalog.Catalog.Name, m.selectedCatalog.Addon.Name())
+		if m.catalogGenre != "" {
+			info += " • " + m.catalogGenre
+		}
+		info += fmt.Sprintf(" • %d loaded", len(m.catalogMetas))
+		b.WriteString(DimStyle.Render(info) + "\n")
+	}
+
+	b.WriteString(m.catalogItemsList.View())
+	b.WriteString("\n")
+
+	if m.statusMsg != "" {
+		b.WriteString(StatusStyle.Render(m.statusMsg) + "\n")
+	}
+
+	if m.errorMsg != "" {
+		b.WriteString(ErrorStyle.Render(m.errorMsg) + "\n")
+	}
+
+	help := "enter: select • " + bestKeys(config.ScopeCatalogItems) + ": play/download best • " + keyFor(config.ScopeCatalogItems, "load_more") + ": load more • "
+	if m.selectedCatalog != nil && len(m.selectedCatalog.Genres()) > 0 {
+		help += keyFor(config.ScopeCatalogItems, "next_genre") + ": next genre • "
+	}
+	help += "esc: back • " + keyFor(config.ScopeCatalogItems, "quit") + ": quit"
+	b.WriteString(HelpStyle.Render(help))
+
+	return b.String()
+}
+
 func (m Model) seasonsView() string {
 	var b strings.Builder
 
 	if m.loading {
-		loading := m.spinner.View() + " " + m.loadingMsg
+		loading := m.loadingLine()
 		return lipgloss.Place(
 			m.width, m.height,
 			lipgloss.Center, lipgloss.Center,
@@ -85,7 +290,7 @@ func (m Model) seasonsView() string {
 		b.WriteString(ErrorStyle.Render(m.errorMsg) + "\n")
 	}
 
-	help := HelpStyle.Render("enter: select • esc: back • j/k: navigate • q: quit")
+	help := HelpStyle.Render("enter: select • esc: back • j/k: navigate • " + keyFor(config.ScopeSeasons, "quit") + ": quit")
 	b.WriteString(help)
 
 	return b.String()
@@ -95,7 +300,7 @@ func (m Model) episodesView() string {
 	var b strings.Builder
 
 	if m.loading {
-		loading := m.spinner.View() + " " + m.loadingMsg
+		loading := m.loadingLine()
 		return lipgloss.Place(
 			m.width, m.height,
 			lipgloss.Center, lipgloss.Center,
@@ -117,6 +322,10 @@ func (m Model) episodesView() string {
 	b.WriteString(m.episodesList.View())
 	b.WriteString("\n")
 
+	if m.statusMsg != "" {
+		b.WriteString(StatusStyle.Render(m.statusMsg) + "\n")
+	}
+
 	if m.errorMsg != "" {
 		b.WriteString(ErrorStyle.Render(m.errorMsg) + "\n")
 	}
@@ -125,7 +334,8 @@ func (m Model) episodesView() string {
 	if m.isFiltering {
 		help = HelpStyle.Render("enter: apply filter • esc: cancel filter")
 	} else {
-		help = HelpStyle.Render("enter: select • b: batch download • /: filter • esc: back")
+		help = HelpStyle.Render("enter: select • " + bestKeys(config.ScopeEpisodes) + ": play/download best match • " +
+			keyFor(config.ScopeEpisodes, "batch") + ": batch download • " + keyFor(config.ScopeEpisodes, "filter") + ": filter • esc: back")
 	}
 	b.WriteString(help)
 
@@ -136,7 +346,7 @@ func (m Model) streamsView() string {
 	var b strings.Builder
 
 	if m.loading {
-		loading := m.spinner.View() + " " + m.loadingMsg
+		loading := m.loadingLine()
 		return lipgloss.Place(
 			m.width, m.height,
 			lipgloss.Center, lipgloss.Center,
@@ -167,6 +377,17 @@ func (m Model) streamsView() string {
 	b.WriteString(m.streamsList.View())
 	b.WriteString("\n")
 
+	// Show addons that failed while others returned streams
+	for _, failure := range m.streamFailures {
+		b.WriteString(DimStyle.Render("  ✗ "+failure.Error()) + "\n")
+	}
+
+	if m.pickingSubs {
+		b.WriteString(m.renderSubtitlePicker())
+	} else if len(m.subLangs) > 0 {
+		b.WriteString(DimStyle.Render("Subtitles: "+strings.Join(m.subLangs, ", ")) + "\n")
+	}
+
 	if m.statusMsg != "" {
 		b.WriteString(SuccessStyle.Render(m.statusMsg) + "\n")
 	}
@@ -176,19 +397,51 @@ func (m Model) streamsView() string {
 	}
 
 	var help string
-	if m.isFiltering {
+	if m.pickingSubs {
+		help = HelpStyle.Render("space: toggle language • c: clear • enter/esc/" + keyFor(config.ScopeStreams, "subtitles") + ": done")
+	} else if m.isFiltering {
 		help = HelpStyle.Render("enter: apply filter • esc: cancel filter")
 	} else {
-		help = HelpStyle.Render("p/enter: play • d: download • /: filter • esc: back • q: quit")
+		help = HelpStyle.Render(keyFor(config.ScopeStreams, "play") + "/enter: play • " + keyFor(config.ScopeStreams, "download") + ": download • " +
+			keyFor(config.ScopeStreams, "subtitles") + ": subtitles • " + keyFor(config.ScopeStreams, "sort") + "/" + keyFor(config.ScopeStreams, "reverse_sort") + ": sort • " +
+			keyFor(config.ScopeStreams, "cycle_profile") + ": profile • " + keyFor(config.ScopeStreams, "filter") + ": filter • esc: back • " +
+			keyFor(config.ScopeStreams, "quit") + ": quit")
 	}
 	b.WriteString(help)
 
 	return b.String()
 }
 
-func (m Model) renderTabBar() string {
-	var mainTab, downloadsTab string
+func (m Model) renderSubtitlePicker() string {
+	var b strings.Builder
+
+	b.WriteString(StatusStyle.Render("Subtitle languages") + "\n")
+	langs, counts := apiutils.SubtitleLanguages(m.subtitles)
+	for i, lang := range langs {
+		checkbox := "[ ]"
+		for _, l := range m.subLangs {
+			if l == lang {
+				checkbox = "[✓]"
+			}
+		}
+
+		selector := "  "
+		nameStyle := NormalStyle
+		if i == m.subPickerIdx {
+			selector = "› "
+			nameStyle = SelectedStyle
+		}
+		b.WriteString(fmt.Sprintf("%s%s %s %s\n", selector, checkbox, nameStyle.Render(lang), DimStyle.Render(fmt.Sprintf("(%d)", counts[lang]))))
+	}
+	return b.String()
+}
+
+// bestKeys names the play and download best match keys in scope.
+func bestKeys(scope string) string {
+	return keyFor(scope, "play_best") + "/" + keyFor(scope, "download_best")
+}
 
+func (m Model) renderTabBar() string {
 	// Count active downloads for badge
 	activeCount := m.activeDownloadCount()
 	downloadsLabel := "Downloads"
@@ -197,16 +450,26 @@ func (m Model) renderTabBar() string {
 	} else if len(m.downloads) > 0 {
 		downloadsLabel = fmt.Sprintf("Downloads (%d)", len(m.downloads))
 	}
+	addonsLabel := fmt.Sprintf("Addons (%d)", len(config.EnabledAddons()))
 
-	if m.currentTab == MainTab {
-		mainTab = TabActiveStyle.Render("Main")
-		downloadsTab = TabInactiveStyle.Render(downloadsLabel)
-	} else {
-		mainTab = TabInactiveStyle.Render("Main")
-		downloadsTab = TabActiveStyle.Render(downloadsLabel)
+	tabs := []string{}
+	for tab, label := range []string{"Main", downloadsLabel, addonsLabel} {
+		style := TabInactiveStyle
+		if Tab(tab) == m.currentTab {
+			style = TabActiveStyle
+		}
+		if tab > 0 {
+			tabs = append(tabs, " ")
+		}
+		tabs = append(tabs, style.Render(label))
 	}
 
-	return lipgloss.JoinHorizontal(lipgloss.Top, mainTab, " ", downloadsTab, HelpStyle.Render("  tab: switch"))
+	if m.offline {
+		tabs = append(tabs, HelpStyle.Render("  tab: switch  "), ErrorStyle.Render("OFFLINE"))
+	} else {
+		tabs = append(tabs, HelpStyle.Render("  tab: switch"))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
 }
 
 func (m Model) downloadsPageView() string {
@@ -216,7 +479,7 @@ func (m Model) downloadsPageView() string {
 	b.WriteString(title + "\n\n")
 
 	if len(m.downloads) == 0 {
-		b.WriteString(DimStyle.Render("No downloads yet. Press 'd' on a stream to start downloading.") + "\n")
+		b.WriteString(DimStyle.Render("No downloads yet. Press '"+keyFor(config.ScopeStreams, "download")+"' on a stream to start downloading.") + "\n")
 	} else {
 		for i, d := range m.downloads {
 			isSelected := i == m.selectedDownloadIdx
@@ -225,7 +488,8 @@ func (m Model) downloadsPageView() string {
 	}
 
 	b.WriteString("\n")
-	help := HelpStyle.Render("j/k: navigate • x: cancel download • esc/q: back to main")
+	help := HelpStyle.Render("j/k: navigate • " + keyFor(config.ScopeDownloads, "play") + ": play • " +
+		keyFor(config.ScopeDownloads, "cancel_download") + ": cancel download • esc/" + keyFor(config.ScopeDownloads, "quit") + ": back to main")
 	b.WriteString(help)
 
 	// Use consistent height with other views
@@ -240,7 +504,7 @@ func (m Model) batchInputView() string {
 	var b strings.Builder
 
 	if m.loading {
-		loading := m.spinner.View() + " " + m.loadingMsg
+		loading := m.loadingLine()
 		return lipgloss.Place(
 			m.width, m.height,
 			lipgloss.Center, lipgloss.Center,
@@ -383,6 +647,13 @@ func (m Model) renderDownloadItem(d Download, isSelected bool) string {
 		style = DownloadFailedStyle
 		statusIcon = "⊘"
 		statusText = "Cancelled"
+	case DownloadHandedOff:
+		style = DownloadCompleteStyle
+		statusIcon = "→"
+		statusText = "Sent to " + d.Client
+	}
+	if d.Client != "" && d.Status != DownloadHandedOff {
+		statusText += " • " + d.Client
 	}
 
 	// Truncate name if too long
@@ -0,0 +1,228 @@
+package apiutils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var ErrUnsupported = errors.New("resource not provided by addon")
+
+// ManifestResource describes one resource an addon serves. Manifests may list
+// resources as plain names ("stream") or as objects that narrow the types and
+// id prefixes for that resource only.
+type ManifestResource struct {
+	Name       string   `json:"name"`
+	Types      []string `json:"types"`
+	IdPrefixes []string `json:"idPrefixes"`
+}
+
+func (r *ManifestResource) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		r.Name = name
+		return nil
+	}
+	type plain ManifestResource
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+type CatalogExtra struct {
+	Name       string   `json:"name"`
+	IsRequired bool     `json:"isRequired"`
+	Options    []string `json:"options"`
+}
+
+type ManifestCatalog struct {
+	Type  string         `json:"type"`
+	Id    string         `json:"id"`
+	Name  string         `json:"name"`
+	Extra []CatalogExtra `json:"extra"`
+}
+
+type Manifest struct {
+	Id          string             `json:"id"`
+	Version     string             `json:"version"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Resources   []ManifestResource `json:"resources"`
+	Types       []string           `json:"types"`
+	IdPrefixes  []string           `json:"idPrefixes"`
+	Catalogs    []ManifestCatalog  `json:"catalogs"`
+}
+
+// Validate checks that the manifest has the fields Stremio requires of one.
+func (m Manifest) Validate() error {
+	var missing []string
+	if m.Id == "" {
+		missing = append(missing, "id")
+	}
+	if m.Name == "" {
+		missing = append(missing, "name")
+	}
+	if m.Version == "" {
+		missing = append(missing, "version")
+	}
+	if len(m.Resources) == 0 {
+		missing = append(missing, "resources")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("manifest has no %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// ResourceNames lists the resources the addon serves, in manifest order.
+func (m Manifest) ResourceNames() []string {
+	names := make([]string, 0, len(m.Resources))
+	for _, r := range m.Resources {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+// Addon is a Stremio addon reachable at BaseUrl (the manifest URL without
+// the trailing /manifest.json).
+type Addon struct {
+	BaseUrl  string
+	Manifest Manifest
+
+	client *Client
+}
+
+// NormalizeAddonUrl turns a manifest URL or stremio:// link into the base
+// URL that resource paths are appended to.
+func NormalizeAddonUrl(addonUrl string) string {
+	u := strings.TrimSpace(addonUrl)
+	if strings.HasPrefix(u, "stremio://") {
+		u = "https://" + strings.TrimPrefix(u, "stremio://")
+	}
+	u = strings.TrimSuffix(u, "/")
+	u = strings.TrimSuffix(u, "/manifest.json")
+	return strings.TrimSuffix(u, "/")
+}
+
+// LoadAddon fetches and parses the addon manifest. Manifests are kept for the
+// lifetime of the process, so repeated calls only hit the network once unless
+// ctx forces a refresh.
+func (c *Client) LoadAddon(ctx context.Context, addonUrl string) (*Addon, error) {
+	base := NormalizeAddonUrl(addonUrl)
+	if base == "" {
+		return nil, fmt.Errorf("no addon URL configured")
+	}
+
+	c.mu.Lock()
+	a, ok := c.addons[base]
+	c.mu.Unlock()
+	if ok && !isRefresh(ctx) {
+		return a, nil
+	}
+
+	var manifest Manifest
+	if err := c.getJSON(ctx, KindManifest, base+"/manifest.json", &manifest); err != nil {
+		return nil, fmt.Errorf("manifest: %w", err)
+	}
+	a = &Addon{BaseUrl: base, Manifest: manifest, client: c}
+
+	c.mu.Lock()
+	c.addons[base] = a
+	c.mu.Unlock()
+	return a, nil
+}
+
+// CheckAddon fetches a fresh copy of the addon's manifest and validates it,
+// for addons about to be installed.
+func (c *Client) CheckAddon(ctx context.Context, addonUrl string) (*Addon, error) {
+	a, err := c.LoadAddon(WithRefresh(ctx), addonUrl)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.Manifest.Validate(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Name returns the manifest name, falling back to the addon URL.
+func (a *Addon) Name() string {
+	if a.Manifest.Name != "" {
+		return a.Manifest.Name
+	}
+	return a.BaseUrl
+}
+
+// Supports reports whether the addon declares the resource for the given
+// content type and id. Types and id prefixes set on the resource itself take
+// precedence over the manifest-wide ones. A manifest may list the resource
+// several times with different types and prefixes; any of them will do.
+func (a *Addon) Supports(resource, contentType, id string) bool {
+	for _, r := range a.Manifest.Resources {
+		if r.Name != resource {
+			continue
+		}
+		types := r.Types
+		if types == nil {
+			types = a.Manifest.Types
+		}
+		prefixes := r.IdPrefixes
+		if prefixes == nil {
+			prefixes = a.Manifest.IdPrefixes
+		}
+		if matchesType(types, contentType) && matchesPrefix(prefixes, id) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesType(types []string, contentType string) bool {
+	for _, t := range types {
+		if t == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesPrefix(prefixes []string, id string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(id, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceUrl builds {base}/{resource}/{type}/{id}[/{extra}].json
+func (a *Addon) resourceUrl(resource, contentType, id string, extra url.Values) string {
+	u := fmt.Sprintf("%s/%s/%s/%s", a.BaseUrl, resource, url.PathEscape(contentType), url.PathEscape(id))
+	if len(extra) > 0 {
+		u += "/" + extra.Encode()
+	}
+	return u + ".json"
+}
+
+// Streams asks the addon for streams of the given title. Ids for episodes use
+// the usual Stremio form "tt1234567:1:3".
+func (a *Addon) Streams(ctx context.Context, contentType, id string) ([]AlcSearchResult, error) {
+	if !a.Supports("stream", contentType, id) {
+		return []AlcSearchResult{}, ErrUnsupported
+	}
+
+	var response struct {
+		Streams []AlcSearchResult `json:"streams"`
+	}
+	if err := a.client.getJSON(ctx, KindStream, a.resourceUrl("stream", contentType, id, nil), &response); err != nil {
+		return []AlcSearchResult{}, err
+	}
+	for i := range response.Streams {
+		response.Streams[i].Addon = a.Name()
+	}
+	return response.Streams, nil
+}
@@ -0,0 +1,46 @@
+package apiutils
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSupports(t *testing.T) {
+	// Streams for IMDB movies and series and for kitsu anime, declared as
+	// separate entries, plus a meta resource taking the manifest defaults
+	const manifest = `{
+		"id": "org.example", "version": "1.0.0", "name": "Example",
+		"types": ["movie", "series", "anime"], "idPrefixes": ["tt", "kitsu:"],
+		"resources": [
+			{"name": "stream", "types": ["movie", "series"], "idPrefixes": ["tt"]},
+			{"name": "stream", "types": ["anime", "series"], "idPrefixes": ["kitsu:"]},
+			"meta"
+		]
+	}`
+	var m Manifest
+	if err := json.Unmarshal([]byte(manifest), &m); err != nil {
+		t.Fatal(err)
+	}
+	addon := &Addon{Manifest: m}
+
+	tests := []struct {
+		resource, contentType, id string
+		want                      bool
+	}{
+		{"stream", "movie", "tt1375666", true},
+		{"stream", "series", "tt0903747:1:1", true},
+		{"stream", "series", "kitsu:1:1", true},
+		{"stream", "anime", "kitsu:1", true},
+		{"stream", "movie", "kitsu:1", false},
+		{"stream", "anime", "tt1375666", false},
+		{"stream", "channel", "tt1375666", false},
+		{"meta", "anime", "kitsu:1", true},
+		{"meta", "movie", "yt_id:UC1", false},
+		{"subtitles", "movie", "tt1375666", false},
+	}
+	for _, tt := range tests {
+		if got := addon.Supports(tt.resource, tt.contentType, tt.id); got != tt.want {
+			t.Errorf("Supports(%q, %q, %q) = %v, want %v", tt.resource, tt.contentType, tt.id, got, tt.want)
+		}
+	}
+}
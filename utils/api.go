@@ -1,11 +1,15 @@
 package apiutils
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+	"unicode"
 
 	config "github.com/rshero/stremio-tui/config"
 )
@@ -17,17 +21,119 @@ const (
 )
 
 type ImdbSearchResult struct {
-	Id            string `json:"id"`
-	Type          string `json:"type"`
-	PrimaryTitle  string `json:"primaryTitle"`
-	OriginalTitle string `json:"originalTitle"`
+	Id            string  `json:"id"`
+	Type          string  `json:"type"`
+	PrimaryTitle  string  `json:"primaryTitle"`
+	OriginalTitle string  `json:"originalTitle"`
+	StartYear     int     `json:"startYear"`
+	EndYear       int     `json:"endYear"`
+	Rating        *Rating `json:"rating"`
+
+	// Filled in by the title endpoint; search results may leave them empty
+	Genres         []string `json:"genres"`
+	RuntimeSeconds int      `json:"runtimeSeconds"`
+	Plot           string   `json:"plot"`
+
+	// AddonType is the Stremio content type of titles from addon catalogs
+	// and links, e.g. "channel" or "tv"; empty for IMDB titles
+	AddonType string `json:"addonType,omitempty"`
+}
+
+type Rating struct {
+	AggregateRating float64 `json:"aggregateRating"`
+	VoteCount       int     `json:"voteCount"`
+}
+
+// SearchFilter narrows search results. Zero fields don't filter. Filters are
+// sent to the API and applied again to what it returns, since the search
+// endpoint may not honour them.
+type SearchFilter struct {
+	Types     []string // IMDB title types, e.g. movie, tvSeries
+	StartYear int
+	EndYear   int
+	MinRating float64
+}
+
+// IsZero reports whether the filter lets everything through.
+func (f SearchFilter) IsZero() bool {
+	return len(f.Types) == 0 && f.StartYear == 0 && f.EndYear == 0 && f.MinRating == 0
+}
+
+// Match reports whether a title passes the filter. Titles without a year or
+// rating fail the year range and minimum rating.
+func (f SearchFilter) Match(r ImdbSearchResult) bool {
+	if len(f.Types) > 0 && !matchesType(f.Types, r.Type) {
+		return false
+	}
+	if f.StartYear > 0 && (r.StartYear == 0 || r.StartYear < f.StartYear) {
+		return false
+	}
+	if f.EndYear > 0 && (r.StartYear == 0 || r.StartYear > f.EndYear) {
+		return false
+	}
+	if f.MinRating > 0 && (r.Rating == nil || r.Rating.AggregateRating < f.MinRating) {
+		return false
+	}
+	return true
+}
+
+// apply adds the filter to the query in the API's list parameters, where
+// title types are spelled like TV_SERIES.
+func (f SearchFilter) apply(params url.Values) {
+	for _, t := range f.Types {
+		params.Add("types", titleTypeParam(t))
+	}
+	if f.StartYear > 0 {
+		params.Set("startYear", strconv.Itoa(f.StartYear))
+	}
+	if f.EndYear > 0 {
+		params.Set("endYear", strconv.Itoa(f.EndYear))
+	}
+	if f.MinRating > 0 {
+		params.Set("minAggregateRating", strconv.FormatFloat(f.MinRating, 'f', -1, 64))
+	}
+}
+
+// titleTypeParam turns "tvMiniSeries" into "TV_MINI_SERIES".
+func titleTypeParam(t string) string {
+	var b strings.Builder
+	for i, r := range t {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+func (f SearchFilter) filter(titles []ImdbSearchResult) []ImdbSearchResult {
+	if f.IsZero() {
+		return titles
+	}
+	kept := []ImdbSearchResult{}
+	for _, t := range titles {
+		if f.Match(t) {
+			kept = append(kept, t)
+		}
+	}
+	return kept
 }
 
 type BehaviorHints struct {
-	BingeGroup string `json:"bingeGroup"`
-	VideoHash  string `json:"videoHash"`
-	VideoSize  int64  `json:"videoSize"`
-	Filename   string `json:"filename"`
+	BingeGroup       string       `json:"bingeGroup"`
+	VideoHash        string       `json:"videoHash"`
+	VideoSize        int64        `json:"videoSize"`
+	Filename         string       `json:"filename"`
+	NotWebReady      bool         `json:"notWebReady"`
+	CountryWhitelist []string     `json:"countryWhitelist"`
+	ProxyHeaders     ProxyHeaders `json:"proxyHeaders"`
+}
+
+// ProxyHeaders are headers the stream needs on requests to it, and headers
+// a proxy should set on its responses.
+type ProxyHeaders struct {
+	Request  map[string]string `json:"request"`
+	Response map[string]string `json:"response"`
 }
 
 type Season struct {
@@ -37,6 +143,7 @@ type Season struct {
 
 type Episode struct {
 	Id            string `json:"id"`
+	VideoId       string `json:"-"` // addon video id, set when loaded from a meta resource
 	Title         string `json:"title"`
 	Season        string `json:"season"`
 	EpisodeNumber int    `json:"episodeNumber"`
@@ -47,118 +154,219 @@ type Episode struct {
 	} `json:"rating"`
 }
 
+// StreamId returns the id addons expect when asking for the episode's
+// streams.
+func (e Episode) StreamId(titleId string) string {
+	if e.VideoId != "" {
+		return e.VideoId
+	}
+	return fmt.Sprintf("%s:%s:%d", titleId, e.Season, e.EpisodeNumber)
+}
+
+// AlcSearchResult is a Stremio stream object. Exactly one of Url, YtId,
+// InfoHash or ExternalUrl says where the stream lives; see Kind.
 type AlcSearchResult struct {
+	Addon         string        `json:"-"` // name of the addon that returned the stream
 	Name          string        `json:"name"`
+	Title         string        `json:"title"` // older addons use title instead of description
 	Description   string        `json:"description"`
 	Url           string        `json:"url"`
+	YtId          string        `json:"ytId"`
+	InfoHash      string        `json:"infoHash"`
+	FileIdx       *int          `json:"fileIdx"`
+	ExternalUrl   string        `json:"externalUrl"`
+	Sources       []string      `json:"sources"`
+	Subtitles     []Subtitle    `json:"subtitles"`
 	BehaviorHints BehaviorHints `json:"behaviorHints"`
 }
 
-func ImdbSearch(query string, limit int) []ImdbSearchResult {
-	apiUrl := fmt.Sprintf("%s/search/titles?query=%s&limit=%d", config.IMDB_API_URL, url.QueryEscape(query), limit)
-	r, err := http.Get(apiUrl)
-	if err != nil {
-		fmt.Printf("Error making request: %v", err)
-		return []ImdbSearchResult{}
+// ImdbSearch returns up to limit titles matching query. An empty result with
+// a nil error means nothing matched.
+func (c *Client) ImdbSearch(ctx context.Context, query string, limit int) ([]ImdbSearchResult, error) {
+	page, err := c.ImdbSearchPage(ctx, query, limit, SearchFilter{}, SearchCursor{})
+	return page.Titles, err
+}
+
+// SearchCursor marks where the next page of search results starts. The API's
+// nextPageToken is used when it sends one; otherwise the next page is asked
+// for by raising the limit and skipping the Offset titles already loaded.
+type SearchCursor struct {
+	Token  string
+	Offset int
+}
+
+// SearchPage is one page of search results. More is false on the last page.
+// Titles the filter dropped aren't included, so a page may be short or empty
+// while More is still true.
+type SearchPage struct {
+	Titles []ImdbSearchResult
+	Next   SearchCursor
+	More   bool
+}
+
+// ImdbSearchPage returns up to limit titles matching query and filter,
+// starting at cursor (the zero cursor for the first page).
+func (c *Client) ImdbSearchPage(ctx context.Context, query string, limit int, filter SearchFilter, cursor SearchCursor) (SearchPage, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	filter.apply(params)
+	if cursor.Token != "" {
+		params.Set("limit", strconv.Itoa(limit))
+		params.Set("pageToken", cursor.Token)
+	} else {
+		params.Set("limit", strconv.Itoa(cursor.Offset+limit))
 	}
-	defer r.Body.Close()
+	apiUrl := config.IMDB_API_URL + "/search/titles?" + params.Encode()
 
 	var response struct {
-		Titles []ImdbSearchResult `json:"titles"`
+		Titles        []ImdbSearchResult `json:"titles"`
+		NextPageToken string             `json:"nextPageToken"`
+	}
+	err := c.getJSON(ctx, KindSearch, apiUrl, &response)
+	if errors.Is(err, ErrOffline) && c.cache != nil {
+		// Everything cached that matches comes back as one page
+		if cursor != (SearchCursor{}) {
+			return SearchPage{Titles: []ImdbSearchResult{}}, nil
+		}
+		return SearchPage{Titles: filter.filter(c.cache.searchTitles(query))}, nil
 	}
-	err = json.NewDecoder(r.Body).Decode(&response)
 	if err != nil {
-		fmt.Printf("Error decoding JSON: %v", err)
-		return []ImdbSearchResult{}
+		return SearchPage{Titles: []ImdbSearchResult{}}, err
+	}
+
+	if response.NextPageToken != "" {
+		return SearchPage{
+			Titles: filter.filter(response.Titles),
+			Next:   SearchCursor{Token: response.NextPageToken, Offset: cursor.Offset + len(response.Titles)},
+			More:   true,
+		}, nil
+	}
+	if cursor.Token != "" {
+		return SearchPage{Titles: filter.filter(response.Titles)}, nil
 	}
 
-	return response.Titles
+	// Without page tokens the response repeats the titles already loaded
+	titles := response.Titles
+	if len(titles) > cursor.Offset {
+		titles = titles[cursor.Offset:]
+	} else {
+		titles = []ImdbSearchResult{}
+	}
+	return SearchPage{
+		Titles: filter.filter(titles),
+		Next:   SearchCursor{Offset: cursor.Offset + len(titles)},
+		More:   len(response.Titles) == cursor.Offset+limit,
+	}, nil
 }
 
-func AlcStream(id string) ([]AlcSearchResult, error) {
-	apiUrl := fmt.Sprintf("%s%s.json", config.ALC_ADDON_URL, id)
+// StremioType returns the content type addons are asked for: the addon's
+// own type when the title came from one, otherwise the IMDB type mapped
+// onto movie or series.
+func (r ImdbSearchResult) StremioType() string {
+	if r.AddonType != "" {
+		return r.AddonType
+	}
+	switch r.Type {
+	case "tvSeries", "tvMiniSeries":
+		return "series"
+	default:
+		return "movie"
+	}
+}
 
-	backoff := initialBackoff
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		r, err := http.Get(apiUrl)
-		if err != nil {
-			return []AlcSearchResult{}, fmt.Errorf("request failed: %v", err)
-		}
+// AddonError records a failed request to one addon while others succeeded.
+type AddonError struct {
+	Addon string
+	Err   error
+}
 
-		// Handle rate limiting (429)
-		if r.StatusCode == http.StatusTooManyRequests {
-			r.Body.Close()
-			if attempt < maxRetries-1 {
-				time.Sleep(backoff)
-				backoff *= 2
-				if backoff > maxBackoff {
-					backoff = maxBackoff
-				}
-				continue
-			}
-			return []AlcSearchResult{}, fmt.Errorf("rate limited (429) after %d retries", maxRetries)
-		}
+func (e AddonError) Error() string {
+	return e.Addon + ": " + e.Err.Error()
+}
 
-		// Handle other HTTP errors
-		if r.StatusCode != http.StatusOK {
-			r.Body.Close()
-			return []AlcSearchResult{}, fmt.Errorf("HTTP %d", r.StatusCode)
-		}
+// AddonUrls returns the enabled addons in their configured order.
+func AddonUrls() []string {
+	return config.EnabledAddons()
+}
 
-		defer r.Body.Close()
+// AggregateStreams queries every configured addon that serves streams for
+// the title concurrently. Results keep the configured addon order; addons
+// that fail are reported separately so the rest can still be shown.
+func (c *Client) AggregateStreams(ctx context.Context, contentType, id string) ([]AlcSearchResult, []AddonError) {
+	urls := AddonUrls()
+	results := make([][]AlcSearchResult, len(urls))
+	errs := make([]*AddonError, len(urls))
 
-		var response struct {
-			Titles []AlcSearchResult `json:"streams"`
-		}
-		err = json.NewDecoder(r.Body).Decode(&response)
-		if err != nil {
-			return []AlcSearchResult{}, fmt.Errorf("decode error: %v", err)
-		}
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		go func(i int, u string) {
+			defer wg.Done()
+			addon, err := c.LoadAddon(ctx, u)
+			if err != nil {
+				errs[i] = &AddonError{Addon: NormalizeAddonUrl(u), Err: err}
+				return
+			}
+			if !addon.Supports("stream", contentType, id) {
+				return
+			}
+			streams, err := addon.Streams(ctx, contentType, id)
+			if err != nil {
+				errs[i] = &AddonError{Addon: addon.Name(), Err: err}
+				return
+			}
+			results[i] = streams
+		}(i, u)
+	}
+	wg.Wait()
 
-		return response.Titles, nil
+	merged := []AlcSearchResult{}
+	var failures []AddonError
+	for i := range urls {
+		merged = append(merged, results[i]...)
+		if errs[i] != nil {
+			failures = append(failures, *errs[i])
+		}
 	}
+	return merged, failures
+}
 
-	return []AlcSearchResult{}, fmt.Errorf("max retries exceeded")
+// AlcStream fetches streams for the title from all configured addons. It
+// only fails when nothing was found and at least one addon errored.
+func (c *Client) AlcStream(ctx context.Context, contentType, id string) ([]AlcSearchResult, error) {
+	results, failures := c.AggregateStreams(ctx, contentType, id)
+	if len(results) == 0 && len(failures) > 0 {
+		msgs := make([]string, len(failures))
+		for i, f := range failures {
+			msgs[i] = f.Error()
+		}
+		return results, fmt.Errorf("%s", strings.Join(msgs, "; "))
+	}
+	return results, nil
 }
 
-func FetchSeasons(id string) []Season {
+func (c *Client) FetchSeasons(ctx context.Context, id string) ([]Season, error) {
 	apiUrl := fmt.Sprintf("%s/titles/%s/seasons", config.IMDB_API_URL, id)
-	r, err := http.Get(apiUrl)
-	if err != nil {
-		fmt.Printf("Error making request: %v", err)
-		return []Season{}
-	}
-	defer r.Body.Close()
 
 	var response struct {
 		Seasons []Season `json:"seasons"`
 	}
-	err = json.NewDecoder(r.Body).Decode(&response)
-	if err != nil {
-		fmt.Printf("Error decoding JSON: %v", err)
-		return []Season{}
+	if err := c.getJSON(ctx, KindMeta, apiUrl, &response); err != nil {
+		return []Season{}, err
 	}
 
-	return response.Seasons
+	return response.Seasons, nil
 }
 
-func FetchEpisodes(id string, season string) []Episode {
+func (c *Client) FetchEpisodes(ctx context.Context, id string, season string) ([]Episode, error) {
 	apiUrl := fmt.Sprintf("%s/titles/%s/episodes?season=%s", config.IMDB_API_URL, id, season)
-	r, err := http.Get(apiUrl)
-	if err != nil {
-		fmt.Printf("Error making request: %v", err)
-		return []Episode{}
-	}
-	defer r.Body.Close()
 
 	var response struct {
 		Episodes []Episode `json:"episodes"`
 	}
-	err = json.NewDecoder(r.Body).Decode(&response)
-	if err != nil {
-		fmt.Printf("Error decoding JSON: %v", err)
-		return []Episode{}
+	if err := c.getJSON(ctx, KindMeta, apiUrl, &response); err != nil {
+		return []Episode{}, err
 	}
 
-	return response.Episodes
+	return response.Episodes, nil
 }
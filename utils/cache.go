@@ -0,0 +1,198 @@
+package apiutils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Kind is the kind of resource a response holds; each has its own TTL.
+type Kind string
+
+const (
+	KindSearch    Kind = "search"
+	KindMeta      Kind = "meta"
+	KindCatalog   Kind = "catalog"
+	KindStream    Kind = "stream"
+	KindSubtitles Kind = "subtitles"
+	KindManifest  Kind = "manifest"
+)
+
+// DefaultTTL is how long a response stays fresh when the addon doesn't send
+// cacheMaxAge.
+var DefaultTTL = map[Kind]time.Duration{
+	KindSearch:    6 * time.Hour,
+	KindMeta:      24 * time.Hour,
+	KindCatalog:   6 * time.Hour,
+	KindStream:    30 * time.Minute,
+	KindSubtitles: 6 * time.Hour,
+	KindManifest:  24 * time.Hour,
+}
+
+// Cache stores raw JSON responses on disk, one file per URL.
+type Cache struct {
+	dir string
+}
+
+// CacheRetention is how long an entry is kept on disk after its TTL and
+// stale windows have passed, so offline mode can still serve it.
+var CacheRetention = 30 * 24 * time.Hour
+
+// OpenCache uses dir, or stremio-tui under the XDG cache dir when empty.
+// Entries past CacheRetention are pruned in the background.
+func OpenCache(dir string) (*Cache, error) {
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(base, "stremio-tui")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	c := &Cache{dir: dir}
+	go c.prune(time.Now())
+	return c, nil
+}
+
+// prune deletes entries kept for longer than CacheRetention beyond every
+// window they could be served in online, and any that don't decode. Without
+// it every search query and suggestion prefix would stay on disk for good.
+func (c *Cache) prune(now time.Time) {
+	files, _ := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var e cacheEntry
+		if err := json.Unmarshal(data, &e); err != nil || e.expired(now) {
+			os.Remove(f)
+		}
+	}
+}
+
+type cacheEntry struct {
+	Url             string          `json:"url"`
+	Kind            Kind            `json:"kind"`
+	FetchedAt       time.Time       `json:"fetchedAt"`
+	MaxAge          time.Duration   `json:"maxAge"`
+	StaleRevalidate time.Duration   `json:"staleRevalidate"`
+	StaleError      time.Duration   `json:"staleError"`
+	Body            json.RawMessage `json:"body"`
+}
+
+type entryState int
+
+const (
+	entryFresh entryState = iota
+	entryRevalidate
+	entryExpired
+)
+
+func (e *cacheEntry) state(now time.Time) entryState {
+	age := now.Sub(e.FetchedAt)
+	switch {
+	case age <= e.MaxAge:
+		return entryFresh
+	case age <= e.MaxAge+e.StaleRevalidate:
+		return entryRevalidate
+	default:
+		return entryExpired
+	}
+}
+
+func (e *cacheEntry) usableOnError(now time.Time) bool {
+	return now.Sub(e.FetchedAt) <= e.MaxAge+e.StaleError
+}
+
+// expired reports whether the entry has been past every window it could be
+// served in online for longer than CacheRetention.
+func (e *cacheEntry) expired(now time.Time) bool {
+	later := now.Add(-CacheRetention)
+	return e.state(later) == entryExpired && !e.usableOnError(later)
+}
+
+func (e *cacheEntry) decode(v any) error {
+	if err := json.Unmarshal(e.Body, v); err != nil {
+		return &DecodeError{Url: e.Url, Err: err}
+	}
+	return nil
+}
+
+func (c *Cache) path(apiUrl string) string {
+	sum := sha256.Sum256([]byte(apiUrl))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// get returns the entry for apiUrl, or nil when there is none.
+func (c *Cache) get(apiUrl string) *cacheEntry {
+	data, err := os.ReadFile(c.path(apiUrl))
+	if err != nil {
+		return nil
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(data, &e); err != nil || e.Url != apiUrl {
+		return nil
+	}
+	return &e
+}
+
+// put stores body, honouring the cacheMaxAge, staleRevalidate and staleError
+// hints (in seconds) that addons may include in their responses. Errors are
+// ignored; the cache is only an optimisation.
+func (c *Cache) put(kind Kind, apiUrl string, body []byte) {
+	var hints struct {
+		CacheMaxAge     *int64 `json:"cacheMaxAge"`
+		StaleRevalidate int64  `json:"staleRevalidate"`
+		StaleError      int64  `json:"staleError"`
+	}
+	json.Unmarshal(body, &hints)
+
+	e := cacheEntry{
+		Url:             apiUrl,
+		Kind:            kind,
+		FetchedAt:       time.Now(),
+		MaxAge:          DefaultTTL[kind],
+		StaleRevalidate: time.Duration(hints.StaleRevalidate) * time.Second,
+		StaleError:      time.Duration(hints.StaleError) * time.Second,
+		Body:            body,
+	}
+	if hints.CacheMaxAge != nil {
+		e.MaxAge = time.Duration(*hints.CacheMaxAge) * time.Second
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(c.dir, "entry-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	tmp.Close()
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	os.Rename(tmp.Name(), c.path(apiUrl))
+}
+
+type refreshKey struct{}
+
+// WithRefresh marks requests made with ctx as forced refreshes: the cache is
+// bypassed and the fresh response replaces the cached one.
+func WithRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, refreshKey{}, true)
+}
+
+func isRefresh(ctx context.Context) bool {
+	refresh, _ := ctx.Value(refreshKey{}).(bool)
+	return refresh
+}
@@ -0,0 +1,69 @@
+package apiutils
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+// writeEntry stores body for apiUrl as if it had been fetched age ago.
+func writeEntry(t *testing.T, c *Cache, kind Kind, apiUrl, body string, age time.Duration) {
+	t.Helper()
+	data, err := json.Marshal(cacheEntry{
+		Url:       apiUrl,
+		Kind:      kind,
+		FetchedAt: time.Now().Add(-age),
+		MaxAge:    DefaultTTL[kind],
+		Body:      json.RawMessage(body),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(c.path(apiUrl), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOfflineServesExpiredEntries(t *testing.T) {
+	dir := t.TempDir()
+	c, err := OpenCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const stale = "https://example.com/stream/movie/tt0111161.json"
+	const ancient = "https://example.com/stream/movie/tt0068646.json"
+	writeEntry(t, c, KindStream, stale, `{"streams":[{"url":"https://cdn/a"}]}`, 48*time.Hour)
+	writeEntry(t, c, KindStream, ancient, `{"streams":[]}`, CacheRetention+time.Hour)
+
+	// A new session opens the cache before going offline
+	c, err = OpenCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.prune(time.Now())
+	client := NewClient(time.Second, "", 10, 10)
+	client.UseCache(c)
+	client.SetOffline(true)
+
+	var v struct {
+		Streams []struct {
+			Url string `json:"url"`
+		} `json:"streams"`
+	}
+	ctx, report := WithCacheReport(context.Background())
+	if err := client.getJSON(ctx, KindStream, stale, &v); err != nil {
+		t.Fatalf("expired entry not served offline: %v", err)
+	}
+	if len(v.Streams) != 1 || v.Streams[0].Url != "https://cdn/a" {
+		t.Errorf("got %+v", v)
+	}
+	if isStale, _ := report.Stale(); !isStale {
+		t.Error("expired entry not reported as stale")
+	}
+
+	if err := client.getJSON(context.Background(), KindStream, ancient, &v); err != ErrOffline {
+		t.Errorf("entry past the retention = %v, want ErrOffline", err)
+	}
+}
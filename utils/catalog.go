@@ -0,0 +1,157 @@
+package apiutils
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MetaPreview is the short form of a title returned by catalog resources.
+type MetaPreview struct {
+	Id          string   `json:"id"`
+	Type        string   `json:"type"`
+	Name        string   `json:"name"`
+	Poster      string   `json:"poster"`
+	ReleaseInfo string   `json:"releaseInfo"`
+	Description string   `json:"description"`
+	Genres      []string `json:"genres"`
+	ImdbRating  string   `json:"imdbRating"`
+	Runtime     string   `json:"runtime"`
+}
+
+// AsSearchResult converts the meta so it can go through the same
+// seasons/episodes/streams flow as an IMDB search result. The addon's type
+// is kept for routing stream and meta requests.
+func (m MetaPreview) AsSearchResult() ImdbSearchResult {
+	t := m.Type
+	if m.Type == "series" {
+		t = "tvSeries"
+	}
+	r := ImdbSearchResult{
+		Id:            m.Id,
+		Type:          t,
+		AddonType:     m.Type,
+		PrimaryTitle:  m.Name,
+		OriginalTitle: m.Name,
+		Genres:        m.Genres,
+		Plot:          m.Description,
+	}
+	r.StartYear, r.EndYear = parseReleaseInfo(m.ReleaseInfo)
+	if rating, err := strconv.ParseFloat(m.ImdbRating, 64); err == nil {
+		r.Rating = &Rating{AggregateRating: rating}
+	}
+	if minutes, err := strconv.Atoi(strings.TrimSuffix(m.Runtime, " min")); err == nil {
+		r.RuntimeSeconds = minutes * 60
+	}
+	return r
+}
+
+// parseReleaseInfo reads "2008", "2008-2013" or "2008–" into start and end
+// years.
+func parseReleaseInfo(info string) (start, end int) {
+	first, rest, _ := strings.Cut(strings.ReplaceAll(info, "–", "-"), "-")
+	start, _ = strconv.Atoi(strings.TrimSpace(first))
+	end, _ = strconv.Atoi(strings.TrimSpace(rest))
+	return start, end
+}
+
+// CatalogRef is a catalog declared in an addon manifest.
+type CatalogRef struct {
+	Addon   *Addon
+	Catalog ManifestCatalog
+}
+
+// Genres returns the genre options the catalog accepts, if any.
+func (c CatalogRef) Genres() []string {
+	for _, e := range c.Catalog.Extra {
+		if e.Name == "genre" {
+			return e.Options
+		}
+	}
+	return nil
+}
+
+// Browsable reports whether the catalog can be listed without a search
+// query or other extra we don't provide.
+func (c CatalogRef) Browsable() bool {
+	for _, e := range c.Catalog.Extra {
+		if e.IsRequired && e.Name != "genre" && e.Name != "skip" {
+			return false
+		}
+	}
+	return true
+}
+
+// Catalogs collects the browsable catalogs of every configured addon,
+// in addon order.
+func (c *Client) Catalogs(ctx context.Context) ([]CatalogRef, []AddonError) {
+	urls := AddonUrls()
+	loaded := make([]*Addon, len(urls))
+	errs := make([]*AddonError, len(urls))
+
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		go func(i int, u string) {
+			defer wg.Done()
+			addon, err := c.LoadAddon(ctx, u)
+			if err != nil {
+				errs[i] = &AddonError{Addon: NormalizeAddonUrl(u), Err: err}
+				return
+			}
+			loaded[i] = addon
+		}(i, u)
+	}
+	wg.Wait()
+
+	catalogs := []CatalogRef{}
+	var failures []AddonError
+	for i := range urls {
+		if errs[i] != nil {
+			failures = append(failures, *errs[i])
+			continue
+		}
+		if !loaded[i].HasResource("catalog") {
+			continue
+		}
+		for _, c := range loaded[i].Manifest.Catalogs {
+			ref := CatalogRef{Addon: loaded[i], Catalog: c}
+			if ref.Browsable() {
+				catalogs = append(catalogs, ref)
+			}
+		}
+	}
+	return catalogs, failures
+}
+
+// HasResource reports whether the manifest lists the resource at all.
+func (a *Addon) HasResource(resource string) bool {
+	for _, r := range a.Manifest.Resources {
+		if r.Name == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// Catalog fetches one page of a catalog. skip is the number of items already
+// loaded and genre, when set, narrows the listing.
+func (a *Addon) Catalog(ctx context.Context, contentType, id string, skip int, genre string) ([]MetaPreview, error) {
+	extra := url.Values{}
+	if genre != "" {
+		extra.Set("genre", genre)
+	}
+	if skip > 0 {
+		extra.Set("skip", strconv.Itoa(skip))
+	}
+
+	var response struct {
+		Metas []MetaPreview `json:"metas"`
+	}
+	if err := a.client.getJSON(ctx, KindCatalog, a.resourceUrl("catalog", contentType, id, extra), &response); err != nil {
+		return []MetaPreview{}, err
+	}
+	return response.Metas, nil
+}
@@ -0,0 +1,32 @@
+package apiutils
+
+import "testing"
+
+func TestAsSearchResultKeepsAddonType(t *testing.T) {
+	tests := []struct {
+		addonType string
+		imdbType  string
+		stremio   string
+	}{
+		{"movie", "movie", "movie"},
+		{"series", "tvSeries", "series"},
+		{"channel", "channel", "channel"},
+		{"tv", "tv", "tv"},
+	}
+	for _, tt := range tests {
+		r := MetaPreview{Id: "yt_id:UC1", Type: tt.addonType, Name: "Title"}.AsSearchResult()
+		if r.Type != tt.imdbType {
+			t.Errorf("%s: Type = %q, want %q", tt.addonType, r.Type, tt.imdbType)
+		}
+		if got := r.StremioType(); got != tt.stremio {
+			t.Errorf("%s: StremioType() = %q, want %q", tt.addonType, got, tt.stremio)
+		}
+		// Details merged over the result don't lose the type
+		if got := (ImdbSearchResult{Id: r.Id}).merge(r).StremioType(); got != tt.stremio {
+			t.Errorf("%s: merged StremioType() = %q, want %q", tt.addonType, got, tt.stremio)
+		}
+	}
+	if got := (ImdbSearchResult{Type: "tvMiniSeries"}).StremioType(); got != "series" {
+		t.Errorf("IMDB mini series: StremioType() = %q, want series", got)
+	}
+}
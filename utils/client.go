@@ -0,0 +1,187 @@
+package apiutils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	config "github.com/rshero/stremio-tui/config"
+)
+
+// Client performs all API and addon requests. Every call takes a context so
+// the TUI can abandon requests when the user leaves the view that made them.
+type Client struct {
+	http        *http.Client
+	userAgent   string
+	rate, burst float64 // per-host request rate limit
+
+	mu       sync.Mutex
+	addons   map[string]*Addon       // manifests by base URL
+	metas    map[string]*Meta        // series metas by id
+	limiters map[string]*hostLimiter // rate limiters by host
+	cache    *Cache                  // nil disables caching
+	offline  bool                    // serve everything from cache
+}
+
+func NewClient(timeout time.Duration, userAgent string, rate, burst float64) *Client {
+	return &Client{
+		http:      &http.Client{Timeout: timeout},
+		userAgent: userAgent,
+		rate:      rate,
+		burst:     burst,
+		addons:    map[string]*Addon{},
+		metas:     map[string]*Meta{},
+		limiters:  map[string]*hostLimiter{},
+	}
+}
+
+// NewDefaultClient creates a client using HTTP_TIMEOUT, USER_AGENT,
+// RATE_LIMIT and RATE_BURST, caching responses under CACHE_DIR (or the XDG
+// cache dir). Caching is skipped if the directory can't be created.
+func NewDefaultClient() *Client {
+	c := NewClient(config.HTTP_TIMEOUT, config.USER_AGENT, config.RATE_LIMIT, config.RATE_BURST)
+	if cache, err := OpenCache(config.CACHE_DIR); err == nil {
+		c.UseCache(cache)
+	}
+	return c
+}
+
+// UseCache stores responses in cache. Passing nil turns caching off.
+func (c *Client) UseCache(cache *Cache) {
+	c.cache = cache
+}
+
+// get performs a GET request with the client's User-Agent.
+func (c *Client) get(ctx context.Context, apiUrl string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	return c.http.Do(req)
+}
+
+// getJSON decodes the JSON response for apiUrl into v, serving it from the
+// cache while the entry for that resource kind is fresh. Entries past their
+// max age but within the addon's staleRevalidate window are served as well
+// and refreshed in the background; staleError lets an old entry stand in when
+// the request fails.
+func (c *Client) getJSON(ctx context.Context, kind Kind, apiUrl string, v any) error {
+	var entry *cacheEntry
+	if c.cache != nil {
+		entry = c.cache.get(apiUrl)
+	}
+
+	if c.Offline() {
+		if entry == nil {
+			return ErrOffline
+		}
+		if entry.state(time.Now()) != entryFresh {
+			markStale(ctx, entry)
+		}
+		return entry.decode(v)
+	}
+
+	if entry != nil && !isRefresh(ctx) {
+		switch entry.state(time.Now()) {
+		case entryFresh:
+			return entry.decode(v)
+		case entryRevalidate:
+			go c.revalidate(kind, apiUrl)
+			markStale(ctx, entry)
+			return entry.decode(v)
+		}
+	}
+
+	body, err := c.fetch(ctx, apiUrl)
+	if err != nil {
+		if entry != nil && ctx.Err() == nil && entry.usableOnError(time.Now()) {
+			markStale(ctx, entry)
+			return entry.decode(v)
+		}
+		return err
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return &DecodeError{Url: apiUrl, Err: err}
+	}
+	if c.cache != nil {
+		c.cache.put(kind, apiUrl, body)
+	}
+	return nil
+}
+
+// revalidate refreshes a stale cache entry without holding up the caller.
+func (c *Client) revalidate(kind Kind, apiUrl string) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.http.Timeout)
+	defer cancel()
+	body, err := c.fetch(ctx, apiUrl)
+	if err != nil || !json.Valid(body) {
+		return
+	}
+	c.cache.put(kind, apiUrl, body)
+}
+
+// fetch performs a GET request and returns the body. Requests go through the
+// host's shared limiter, and a 429 backs off every request to that host for
+// Retry-After (or an exponential backoff) before retrying.
+func (c *Client) fetch(ctx context.Context, apiUrl string) ([]byte, error) {
+	u, err := url.Parse(apiUrl)
+	if err != nil {
+		return nil, &NetworkError{Url: apiUrl, Err: err}
+	}
+	limiter := c.limiter(u.Host)
+
+	backoff := initialBackoff
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if err := limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		r, err := c.get(ctx, apiUrl)
+		if err != nil {
+			return nil, &NetworkError{Url: apiUrl, Err: err}
+		}
+
+		// Handle rate limiting (429)
+		if r.StatusCode == http.StatusTooManyRequests {
+			r.Body.Close()
+			if attempt < maxRetries-1 {
+				wait, ok := retryAfter(r)
+				if !ok {
+					wait = backoff
+					backoff *= 2
+				}
+				if wait > maxBackoff {
+					wait = maxBackoff
+				}
+				limiter.backoff(wait)
+				continue
+			}
+			return nil, &HTTPError{Url: apiUrl, StatusCode: r.StatusCode}
+		}
+		limiter.success()
+
+		// Handle other HTTP errors
+		if r.StatusCode != http.StatusOK {
+			r.Body.Close()
+			return nil, &HTTPError{Url: apiUrl, StatusCode: r.StatusCode}
+		}
+
+		body, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return nil, &NetworkError{Url: apiUrl, Err: err}
+		}
+		return body, nil
+	}
+
+	return nil, fmt.Errorf("max retries exceeded")
+}
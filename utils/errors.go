@@ -0,0 +1,38 @@
+package apiutils
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// NetworkError means the request never got a response: DNS, connection or
+// timeout failures.
+type NetworkError struct {
+	Url string
+	Err error
+}
+
+func (e *NetworkError) Error() string { return fmt.Sprintf("request failed: %v", e.Err) }
+func (e *NetworkError) Unwrap() error { return e.Err }
+
+// HTTPError means the server answered with a non-200 status.
+type HTTPError struct {
+	Url        string
+	StatusCode int
+}
+
+func (e *HTTPError) Error() string {
+	if e.StatusCode == http.StatusTooManyRequests {
+		return fmt.Sprintf("rate limited (429) after %d retries", maxRetries)
+	}
+	return fmt.Sprintf("HTTP %d", e.StatusCode)
+}
+
+// DecodeError means the response body wasn't the JSON we expected.
+type DecodeError struct {
+	Url string
+	Err error
+}
+
+func (e *DecodeError) Error() string { return fmt.Sprintf("decode error: %v", e.Err) }
+func (e *DecodeError) Unwrap() error { return e.Err }
@@ -0,0 +1,72 @@
+package apiutils
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TitleLink is a title (and optionally an episode) named directly instead
+// of searched for.
+type TitleLink struct {
+	Type    string // Stremio type, e.g. "movie" or "series"; empty when the link doesn't say
+	Id      string // title id, e.g. tt0903747
+	VideoId string // episode video id from Stremio links, e.g. tt0903747:1:3
+	Season  int    // 0 when no episode is named
+	Episode int
+}
+
+var (
+	imdbIdRe  = regexp.MustCompile(`^tt\d{7,}$`)
+	imdbUrlRe = regexp.MustCompile(`^(?:https?://)?(?:www\.|m\.)?imdb\.com(?:/[a-z]{2}(?:-[a-z]{2})?)?/title/(tt\d{7,})`)
+)
+
+// ParseTitleLink recognises an IMDB id ("tt0903747"), an IMDB title URL
+// ("https://www.imdb.com/title/tt0903747/", also with a language segment
+// such as /de/) and Stremio detail links
+// ("stremio:///detail/series/tt0903747/tt0903747:1:3", also on
+// web.stremio.com).
+func ParseTitleLink(s string) (TitleLink, bool) {
+	s = strings.TrimSpace(s)
+	if imdbIdRe.MatchString(s) {
+		return TitleLink{Id: s}, true
+	}
+	if m := imdbUrlRe.FindStringSubmatch(s); m != nil {
+		return TitleLink{Id: m[1]}, true
+	}
+
+	var path string
+	switch {
+	case strings.HasPrefix(s, "stremio://"):
+		path = strings.TrimPrefix(s, "stremio://")
+	case strings.Contains(s, "stremio.com/#/"):
+		_, path, _ = strings.Cut(s, "#/")
+	default:
+		return TitleLink{}, false
+	}
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 3 || parts[0] != "detail" {
+		return TitleLink{}, false
+	}
+	for i, p := range parts {
+		if unescaped, err := url.PathUnescape(p); err == nil {
+			parts[i] = unescaped
+		}
+	}
+
+	link := TitleLink{Type: parts[1], Id: parts[2]}
+	if link.Id == "" {
+		return TitleLink{}, false
+	}
+	if len(parts) > 3 && parts[3] != "" && parts[3] != link.Id {
+		link.VideoId = parts[3]
+		// Episode ids look like {id}:{season}:{episode}
+		if fields := strings.Split(link.VideoId, ":"); len(fields) == 3 {
+			link.Season, _ = strconv.Atoi(fields[1])
+			link.Episode, _ = strconv.Atoi(fields[2])
+		}
+	}
+	return link, true
+}
@@ -0,0 +1,41 @@
+package apiutils
+
+import "testing"
+
+func TestParseTitleLink(t *testing.T) {
+	tests := []struct {
+		in   string
+		want TitleLink
+		ok   bool
+	}{
+		{"tt0903747", TitleLink{Id: "tt0903747"}, true},
+		{"  tt10872600 ", TitleLink{Id: "tt10872600"}, true},
+		{"https://www.imdb.com/title/tt0903747/", TitleLink{Id: "tt0903747"}, true},
+		{"https://m.imdb.com/title/tt0903747/?ref_=nv_sr_1", TitleLink{Id: "tt0903747"}, true},
+		{"imdb.com/title/tt0903747", TitleLink{Id: "tt0903747"}, true},
+		{"https://www.imdb.com/de/title/tt0903747/", TitleLink{Id: "tt0903747"}, true},
+		{"https://www.imdb.com/pt-br/title/tt0903747/episodes", TitleLink{Id: "tt0903747"}, true},
+		{"stremio:///detail/movie/tt1375666", TitleLink{Type: "movie", Id: "tt1375666"}, true},
+		{"stremio:///detail/series/tt0903747/tt0903747", TitleLink{Type: "series", Id: "tt0903747"}, true},
+		{"stremio:///detail/series/tt0903747/tt0903747:1:3",
+			TitleLink{Type: "series", Id: "tt0903747", VideoId: "tt0903747:1:3", Season: 1, Episode: 3}, true},
+		{"https://web.stremio.com/#/detail/series/tt0903747/tt0903747%3A2%3A5",
+			TitleLink{Type: "series", Id: "tt0903747", VideoId: "tt0903747:2:5", Season: 2, Episode: 5}, true},
+		{"stremio:///detail/series/kitsu:1/kitsu:1:1:4",
+			TitleLink{Type: "series", Id: "kitsu:1", VideoId: "kitsu:1:1:4"}, true},
+		{"breaking bad", TitleLink{}, false},
+		{"tt123", TitleLink{}, false},
+		{"tt0903747 breaking bad", TitleLink{}, false},
+		{"https://www.imdb.com/name/nm0186505/", TitleLink{}, false},
+		{"https://notimdb.com/title/tt0903747/", TitleLink{}, false},
+		{"stremio:///search?search=bad", TitleLink{}, false},
+		{"stremio:///detail/series", TitleLink{}, false},
+		{"https://web.stremio.com/#/discover", TitleLink{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseTitleLink(tt.in)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseTitleLink(%q) = %+v, %v, want %+v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package apiutils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	config "github.com/rshero/stremio-tui/config"
+)
+
+// MetaVideo is one entry of a meta's videos array. For series these are the
+// episodes; Season 0 holds specials.
+type MetaVideo struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Title     string `json:"title"`
+	Season    int    `json:"season"`
+	Episode   int    `json:"episode"`
+	Number    int    `json:"number"`
+	Overview  string `json:"overview"`
+	Released  string `json:"released"`
+	Thumbnail string `json:"thumbnail"`
+}
+
+type Meta struct {
+	MetaPreview
+	Videos         []MetaVideo     `json:"videos"`
+	TrailerStreams []TrailerStream `json:"trailerStreams"`
+}
+
+// TrailerStream is a YouTube trailer listed in a meta.
+type TrailerStream struct {
+	Title string `json:"title"`
+	YtId  string `json:"ytId"`
+}
+
+// Meta fetches the full meta object for a title.
+func (a *Addon) Meta(ctx context.Context, contentType, id string) (*Meta, error) {
+	if !a.Supports("meta", contentType, id) {
+		return nil, ErrUnsupported
+	}
+
+	var response struct {
+		Meta Meta `json:"meta"`
+	}
+	if err := a.client.getJSON(ctx, KindMeta, a.resourceUrl("meta", contentType, id, nil), &response); err != nil {
+		return nil, err
+	}
+	return &response.Meta, nil
+}
+
+// metaAddon picks the addon that serves metadata for the title:
+// META_ADDON_URL when it declares the meta resource for the type and id,
+// otherwise the first configured addon that does.
+func (c *Client) metaAddon(ctx context.Context, contentType, id string) (*Addon, error) {
+	var loadErr error
+	if config.META_ADDON_URL != "" {
+		addon, err := c.LoadAddon(ctx, config.META_ADDON_URL)
+		if err == nil && addon.Supports("meta", contentType, id) {
+			return addon, nil
+		}
+		loadErr = err
+	}
+	for _, u := range AddonUrls() {
+		addon, err := c.LoadAddon(ctx, u)
+		if err != nil {
+			continue
+		}
+		if addon.Supports("meta", contentType, id) {
+			return addon, nil
+		}
+	}
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	return nil, fmt.Errorf("no addon provides metadata for %s", id)
+}
+
+// seriesMeta loads the series meta once per session, since seasons and
+// episodes are both built from the same videos array.
+func (c *Client) seriesMeta(ctx context.Context, id string) (*Meta, error) {
+	c.mu.Lock()
+	meta, ok := c.metas[id]
+	c.mu.Unlock()
+	if ok && !isRefresh(ctx) {
+		return meta, nil
+	}
+
+	addon, err := c.metaAddon(ctx, "series", id)
+	if err != nil {
+		return nil, err
+	}
+	meta, err = addon.Meta(ctx, "series", id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.metas[id] = meta
+	c.mu.Unlock()
+	return meta, nil
+}
+
+func (v MetaVideo) episodeNumber() int {
+	if v.Episode > 0 {
+		return v.Episode
+	}
+	return v.Number
+}
+
+// AddonSeasons builds the season list from the addon meta's videos.
+func (c *Client) AddonSeasons(ctx context.Context, id string) ([]Season, error) {
+	meta, err := c.seriesMeta(ctx, id)
+	if err != nil {
+		return []Season{}, err
+	}
+
+	counts := map[int]int{}
+	for _, v := range meta.Videos {
+		counts[v.Season]++
+	}
+	numbers := make([]int, 0, len(counts))
+	for n := range counts {
+		numbers = append(numbers, n)
+	}
+	// Specials (season 0) go last
+	sort.Slice(numbers, func(i, j int) bool {
+		if numbers[i] == 0 || numbers[j] == 0 {
+			return numbers[j] == 0 && numbers[i] != 0
+		}
+		return numbers[i] < numbers[j]
+	})
+
+	seasons := make([]Season, len(numbers))
+	for i, n := range numbers {
+		seasons[i] = Season{Season: strconv.Itoa(n), EpisodeCount: counts[n]}
+	}
+	return seasons, nil
+}
+
+// AddonEpisodes builds the episodes of one season from the addon meta.
+func (c *Client) AddonEpisodes(ctx context.Context, id string, season string) ([]Episode, error) {
+	meta, err := c.seriesMeta(ctx, id)
+	if err != nil {
+		return []Episode{}, err
+	}
+
+	episodes := []Episode{}
+	for _, v := range meta.Videos {
+		if strconv.Itoa(v.Season) != season {
+			continue
+		}
+		title := v.Name
+		if title == "" {
+			title = v.Title
+		}
+		episodes = append(episodes, Episode{
+			Id:            v.Id,
+			VideoId:       v.Id,
+			Title:         title,
+			Season:        season,
+			EpisodeNumber: v.episodeNumber(),
+			Plot:          v.Overview,
+		})
+	}
+	sort.SliceStable(episodes, func(i, j int) bool {
+		return episodes[i].EpisodeNumber < episodes[j].EpisodeNumber
+	})
+	return episodes, nil
+}
+
+// useAddonMeta reports whether a title's seasons and episodes come from the
+// addon meta source: when META_SOURCE is "addon", and always for ids the
+// IMDB API doesn't know, such as kitsu ids from a catalog.
+func useAddonMeta(id string) bool {
+	return config.META_SOURCE == "addon" || !strings.HasPrefix(id, "tt")
+}
+
+// LoadSeasons returns seasons from the metadata source chosen by
+// META_SOURCE ("imdb" or "addon") and the id; see useAddonMeta.
+func (c *Client) LoadSeasons(ctx context.Context, id string) ([]Season, error) {
+	if useAddonMeta(id) {
+		return c.AddonSeasons(ctx, id)
+	}
+	return c.FetchSeasons(ctx, id)
+}
+
+// LoadEpisodes returns episodes from the metadata source chosen by
+// META_SOURCE ("imdb" or "addon") and the id; see useAddonMeta.
+func (c *Client) LoadEpisodes(ctx context.Context, id string, season string) ([]Episode, error) {
+	if useAddonMeta(id) {
+		return c.AddonEpisodes(ctx, id, season)
+	}
+	return c.FetchEpisodes(ctx, id, season)
+}
@@ -0,0 +1,67 @@
+package apiutils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	config "github.com/rshero/stremio-tui/config"
+)
+
+// fakeAddons serves Cinemeta, which only knows IMDB ids, and a kitsu addon.
+func fakeAddons(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /cinemeta/manifest.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"cinemeta","name":"Cinemeta","version":"1.0.0","resources":["meta"],"types":["movie","series"],"idPrefixes":["tt"]}`))
+	})
+	mux.HandleFunc("GET /cinemeta/meta/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Cinemeta asked for %s", r.URL.Path)
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("GET /kitsu/manifest.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"kitsu","name":"Kitsu","version":"1.0.0","resources":[{"name":"meta","types":["series","anime"],"idPrefixes":["kitsu:"]}],"types":["anime"]}`))
+	})
+	mux.HandleFunc("GET /kitsu/meta/series/kitsu:1.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"meta":{"id":"kitsu:1","type":"series","name":"Cowboy Bebop","videos":[
+			{"id":"kitsu:1:1","season":1,"episode":1,"title":"Asteroid Blues"},
+			{"id":"kitsu:1:2","season":1,"episode":2,"title":"Stray Dog Strut"}]}}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLoadSeasonsNonImdbId(t *testing.T) {
+	srv := fakeAddons(t)
+	metaAddonUrl, metaSource, file := config.META_ADDON_URL, config.META_SOURCE, config.File
+	t.Cleanup(func() {
+		config.META_ADDON_URL, config.META_SOURCE, config.File = metaAddonUrl, metaSource, file
+		config.SaveAddons(nil)
+	})
+	config.META_ADDON_URL = srv.URL + "/cinemeta/manifest.json"
+	config.META_SOURCE = "imdb"
+	config.File = filepath.Join(t.TempDir(), "config.json")
+	if err := config.SaveAddons([]config.Addon{{Url: srv.URL + "/kitsu/manifest.json"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewClient(time.Second, "", 100, 100)
+	seasons, err := c.LoadSeasons(context.Background(), "kitsu:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seasons) != 1 || seasons[0].Season != "1" || seasons[0].EpisodeCount != 2 {
+		t.Errorf("seasons = %+v", seasons)
+	}
+	episodes, err := c.LoadEpisodes(context.Background(), "kitsu:1", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(episodes) != 2 || episodes[1].Title != "Stray Dog Strut" {
+		t.Errorf("episodes = %+v", episodes)
+	}
+}
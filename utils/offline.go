@@ -0,0 +1,101 @@
+package apiutils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrOffline is returned in offline mode for anything that isn't cached.
+var ErrOffline = errors.New("offline: not in cache")
+
+// SetOffline switches the client to serving every request from the cache,
+// whatever the age of the entry, and never touching the network.
+func (c *Client) SetOffline(offline bool) {
+	c.mu.Lock()
+	c.offline = offline
+	c.mu.Unlock()
+}
+
+func (c *Client) Offline() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.offline
+}
+
+// CacheReport collects whether any response behind a request was served from
+// a cache entry past its max age.
+type CacheReport struct {
+	mu     sync.Mutex
+	stale  bool
+	oldest time.Time
+}
+
+type reportKey struct{}
+
+// WithCacheReport returns a context whose requests record stale cache hits
+// in the returned report.
+func WithCacheReport(ctx context.Context) (context.Context, *CacheReport) {
+	r := &CacheReport{}
+	return context.WithValue(ctx, reportKey{}, r), r
+}
+
+// Stale reports whether any stale entry was used, and the oldest fetch time.
+func (r *CacheReport) Stale() (bool, time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stale, r.oldest
+}
+
+func markStale(ctx context.Context, e *cacheEntry) {
+	r, ok := ctx.Value(reportKey{}).(*CacheReport)
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.stale || e.FetchedAt.Before(r.oldest) {
+		r.oldest = e.FetchedAt
+	}
+	r.stale = true
+}
+
+// searchTitles looks through every cached search response for titles
+// matching query, so offline search isn't limited to exact past queries.
+func (c *Cache) searchTitles(query string) []ImdbSearchResult {
+	files, _ := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	query = strings.ToLower(query)
+	seen := map[string]bool{}
+	results := []ImdbSearchResult{}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var e cacheEntry
+		if err := json.Unmarshal(data, &e); err != nil || e.Kind != KindSearch {
+			continue
+		}
+		var response struct {
+			Titles []ImdbSearchResult `json:"titles"`
+		}
+		if err := json.Unmarshal(e.Body, &response); err != nil {
+			continue
+		}
+		for _, t := range response.Titles {
+			if seen[t.Id] {
+				continue
+			}
+			if strings.Contains(strings.ToLower(t.PrimaryTitle), query) || strings.Contains(strings.ToLower(t.OriginalTitle), query) {
+				seen[t.Id] = true
+				results = append(results, t)
+			}
+		}
+	}
+	return results
+}
@@ -0,0 +1,128 @@
+package apiutils
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const minRate = 0.1 // requests per second while a host keeps rate limiting us
+
+// hostLimiter is a token bucket shared by every request to one host. When the
+// host answers 429 the rate is halved and all requests wait out Retry-After;
+// each success recovers some of the rate again.
+type hostLimiter struct {
+	mu           sync.Mutex
+	rate         float64 // current tokens per second
+	maxRate      float64
+	burst        float64
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+}
+
+func newHostLimiter(rate, burst float64) *hostLimiter {
+	return &hostLimiter{rate: rate, maxRate: rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// wait blocks until a request may be sent or ctx is done.
+func (l *hostLimiter) wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.last = now
+
+		var delay time.Duration
+		switch {
+		case now.Before(l.blockedUntil):
+			delay = l.blockedUntil.Sub(now)
+		case l.tokens >= 1:
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		default:
+			delay = time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		}
+		l.mu.Unlock()
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// backoff blocks the host for d and slows it down.
+func (l *hostLimiter) backoff(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+	l.tokens = 0
+	l.rate /= 2
+	if l.rate < minRate {
+		l.rate = minRate
+	}
+}
+
+// success lets the rate creep back towards the configured limit.
+func (l *hostLimiter) success() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate *= 1.1
+	if l.rate > l.maxRate {
+		l.rate = l.maxRate
+	}
+}
+
+// retryAfter reads the Retry-After header, which is either a number of
+// seconds or an HTTP date. It returns false when the header is missing.
+func retryAfter(r *http.Response) (time.Duration, bool) {
+	v := r.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t), true
+	}
+	return 0, false
+}
+
+// limiter returns the shared limiter for the URL's host.
+func (c *Client) limiter(host string) *hostLimiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l, ok := c.limiters[host]
+	if !ok {
+		l = newHostLimiter(c.rate, c.burst)
+		c.limiters[host] = l
+	}
+	return l
+}
+
+// Backoff reports the host we are currently waiting on after a 429 and how
+// long is left. ok is false when no host is backing off.
+func (c *Client) Backoff() (host string, remaining time.Duration, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for h, l := range c.limiters {
+		l.mu.Lock()
+		if left := l.blockedUntil.Sub(now); left > remaining {
+			host, remaining, ok = h, left, true
+		}
+		l.mu.Unlock()
+	}
+	return host, remaining, ok
+}
@@ -0,0 +1,99 @@
+package apiutils
+
+import (
+	"net/url"
+	"strings"
+)
+
+// StreamKind tells how a stream has to be played or downloaded.
+type StreamKind int
+
+const (
+	StreamURL      StreamKind = iota // direct HTTP(S) URL
+	StreamTorrent                    // infoHash, optionally with fileIdx
+	StreamYouTube                    // ytId
+	StreamExternal                   // externalUrl, opened in a browser
+	StreamUnknown
+)
+
+func (k StreamKind) String() string {
+	switch k {
+	case StreamURL:
+		return "url"
+	case StreamTorrent:
+		return "torrent"
+	case StreamYouTube:
+		return "youtube"
+	case StreamExternal:
+		return "external"
+	default:
+		return "unknown"
+	}
+}
+
+func (s AlcSearchResult) Kind() StreamKind {
+	switch {
+	case s.Url != "":
+		return StreamURL
+	case s.InfoHash != "":
+		return StreamTorrent
+	case s.YtId != "":
+		return StreamYouTube
+	case s.ExternalUrl != "":
+		return StreamExternal
+	default:
+		return StreamUnknown
+	}
+}
+
+// Details returns the description, falling back to the deprecated title.
+func (s AlcSearchResult) Details() string {
+	if s.Description != "" {
+		return s.Description
+	}
+	return s.Title
+}
+
+// PlayableUrl is what a player like mpv can open directly: the URL itself or
+// the YouTube watch page (mpv resolves it through yt-dlp).
+func (s AlcSearchResult) PlayableUrl() string {
+	switch s.Kind() {
+	case StreamURL:
+		return s.Url
+	case StreamYouTube:
+		return "https://www.youtube.com/watch?v=" + url.QueryEscape(s.YtId)
+	default:
+		return ""
+	}
+}
+
+// Trackers returns the tracker URLs listed in sources ("tracker:<url>").
+func (s AlcSearchResult) Trackers() []string {
+	var trackers []string
+	for _, src := range s.Sources {
+		if t, ok := strings.CutPrefix(src, "tracker:"); ok {
+			trackers = append(trackers, t)
+		}
+	}
+	return trackers
+}
+
+// MagnetURI builds a magnet link for torrent streams, with the display name
+// and the trackers from sources.
+func (s AlcSearchResult) MagnetURI() string {
+	if s.InfoHash == "" {
+		return ""
+	}
+	magnet := "magnet:?xt=urn:btih:" + s.InfoHash
+	name := s.BehaviorHints.Filename
+	if name == "" {
+		name = s.Name
+	}
+	if name != "" {
+		magnet += "&dn=" + url.QueryEscape(name)
+	}
+	for _, t := range s.Trackers() {
+		magnet += "&tr=" + url.QueryEscape(t)
+	}
+	return magnet
+}
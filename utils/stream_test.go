@@ -0,0 +1,32 @@
+package apiutils
+
+import "testing"
+
+func TestMagnetURI(t *testing.T) {
+	const hash = "0123456789abcdef0123456789abcdef01234567"
+	tests := []struct {
+		name   string
+		stream AlcSearchResult
+		want   string
+	}{
+		{"no hash", AlcSearchResult{Name: "Movie"}, ""},
+		{"hash only", AlcSearchResult{InfoHash: hash}, "magnet:?xt=urn:btih:" + hash},
+		{"name", AlcSearchResult{InfoHash: hash, Name: "Movie 1080p"},
+			"magnet:?xt=urn:btih:" + hash + "&dn=Movie+1080p"},
+		{"filename over name", AlcSearchResult{InfoHash: hash, Name: "Movie",
+			BehaviorHints: BehaviorHints{Filename: "Movie.2020.1080p.mkv"}},
+			"magnet:?xt=urn:btih:" + hash + "&dn=Movie.2020.1080p.mkv"},
+		{"trackers from sources", AlcSearchResult{InfoHash: hash, Sources: []string{
+			"tracker:udp://tracker.example:1337/announce",
+			"dht:" + hash,
+			"tracker:http://other.example/announce?k=v",
+		}}, "magnet:?xt=urn:btih:" + hash +
+			"&tr=udp%3A%2F%2Ftracker.example%3A1337%2Fannounce" +
+			"&tr=http%3A%2F%2Fother.example%2Fannounce%3Fk%3Dv"},
+	}
+	for _, tt := range tests {
+		if got := tt.stream.MagnetURI(); got != tt.want {
+			t.Errorf("%s: MagnetURI() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
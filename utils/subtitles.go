@@ -0,0 +1,139 @@
+package apiutils
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+type Subtitle struct {
+	Addon string `json:"-"` // name of the addon that returned the track
+	Id    string `json:"id"`
+	Url   string `json:"url"`
+	Lang  string `json:"lang"`
+}
+
+// Subtitles asks the addon for subtitle tracks. extra may carry videoHash,
+// videoSize and filename so hash-matched tracks are returned.
+func (a *Addon) Subtitles(ctx context.Context, contentType, id string, extra url.Values) ([]Subtitle, error) {
+	if !a.Supports("subtitles", contentType, id) {
+		return []Subtitle{}, ErrUnsupported
+	}
+
+	var response struct {
+		Subtitles []Subtitle `json:"subtitles"`
+	}
+	if err := a.client.getJSON(ctx, KindSubtitles, a.resourceUrl("subtitles", contentType, id, extra), &response); err != nil {
+		return []Subtitle{}, err
+	}
+	for i := range response.Subtitles {
+		response.Subtitles[i].Addon = a.Name()
+	}
+	return response.Subtitles, nil
+}
+
+// subtitleExtra turns the stream's behavior hints into subtitle extras,
+// leaving out whatever the addon didn't tell us.
+func subtitleExtra(hints BehaviorHints) url.Values {
+	extra := url.Values{}
+	if hints.VideoHash != "" {
+		extra.Set("videoHash", hints.VideoHash)
+	}
+	if hints.VideoSize > 0 {
+		extra.Set("videoSize", strconv.FormatInt(hints.VideoSize, 10))
+	}
+	if hints.Filename != "" {
+		extra.Set("filename", hints.Filename)
+	}
+	return extra
+}
+
+// AggregateSubtitles queries every configured addon that serves subtitles
+// for the title concurrently, in the same way as AggregateStreams.
+func (c *Client) AggregateSubtitles(ctx context.Context, contentType, id string, hints BehaviorHints) ([]Subtitle, []AddonError) {
+	urls := AddonUrls()
+	extra := subtitleExtra(hints)
+	results := make([][]Subtitle, len(urls))
+	errs := make([]*AddonError, len(urls))
+
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		go func(i int, u string) {
+			defer wg.Done()
+			addon, err := c.LoadAddon(ctx, u)
+			if err != nil {
+				errs[i] = &AddonError{Addon: NormalizeAddonUrl(u), Err: err}
+				return
+			}
+			if !addon.Supports("subtitles", contentType, id) {
+				return
+			}
+			subs, err := addon.Subtitles(ctx, contentType, id, extra)
+			if err != nil {
+				errs[i] = &AddonError{Addon: addon.Name(), Err: err}
+				return
+			}
+			results[i] = subs
+		}(i, u)
+	}
+	wg.Wait()
+
+	merged := []Subtitle{}
+	var failures []AddonError
+	for i := range urls {
+		merged = append(merged, results[i]...)
+		if errs[i] != nil {
+			failures = append(failures, *errs[i])
+		}
+	}
+	return merged, failures
+}
+
+// SubtitleLanguages returns the distinct languages with their track counts,
+// sorted by language code.
+func SubtitleLanguages(subs []Subtitle) ([]string, map[string]int) {
+	counts := map[string]int{}
+	for _, s := range subs {
+		counts[s.Lang]++
+	}
+	langs := make([]string, 0, len(counts))
+	for l := range counts {
+		langs = append(langs, l)
+	}
+	sort.Strings(langs)
+	return langs, counts
+}
+
+// MergeSubtitles joins track lists in order, dropping tracks whose URL an
+// earlier list already has.
+func MergeSubtitles(lists ...[]Subtitle) []Subtitle {
+	seen := map[string]bool{}
+	merged := []Subtitle{}
+	for _, list := range lists {
+		for _, s := range list {
+			if !seen[s.Url] {
+				seen[s.Url] = true
+				merged = append(merged, s)
+			}
+		}
+	}
+	return merged
+}
+
+// PickSubtitles returns one track URL per requested language, in the order
+// the languages were given. The first track from the addon order wins.
+func PickSubtitles(subs []Subtitle, langs []string) []string {
+	var urls []string
+	for _, lang := range langs {
+		for _, s := range subs {
+			if s.Lang == lang && s.Url != "" {
+				urls = append(urls, s.Url)
+				break
+			}
+		}
+	}
+	return urls
+}
@@ -0,0 +1,23 @@
+package apiutils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeSubtitles(t *testing.T) {
+	bundled := []Subtitle{{Url: "https://a.example/en.srt", Lang: "eng"}}
+	matched := []Subtitle{{Url: "https://b.example/hash-fr.srt", Lang: "fre"}, {Url: "https://b.example/hash-en.srt", Lang: "eng"}}
+	addon := []Subtitle{{Url: "https://b.example/fr.srt", Lang: "fre"}, {Url: "https://b.example/hash-fr.srt", Lang: "fre"}}
+
+	merged := MergeSubtitles(bundled, matched, addon)
+	want := []Subtitle{bundled[0], matched[0], matched[1], addon[0]}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("merged = %+v, want %+v", merged, want)
+	}
+	// The bundled track still wins its language
+	urls := PickSubtitles(merged, []string{"eng", "fre"})
+	if !reflect.DeepEqual(urls, []string{"https://a.example/en.srt", "https://b.example/hash-fr.srt"}) {
+		t.Errorf("picked %q", urls)
+	}
+}
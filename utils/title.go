@@ -0,0 +1,128 @@
+package apiutils
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	config "github.com/rshero/stremio-tui/config"
+)
+
+// ImdbTitle fetches the full record of a title, with the plot, genres,
+// runtime and rating that search results may leave out.
+func (c *Client) ImdbTitle(ctx context.Context, id string) (ImdbSearchResult, error) {
+	var title ImdbSearchResult
+	apiUrl := fmt.Sprintf("%s/titles/%s", config.IMDB_API_URL, url.PathEscape(id))
+	if err := c.getJSON(ctx, KindMeta, apiUrl, &title); err != nil {
+		return ImdbSearchResult{}, err
+	}
+	return title, nil
+}
+
+// TitleDetails fills in the details of a title: from the IMDB API for IMDB
+// ids, otherwise from the meta addon. Fields the source leaves empty keep
+// the values already in result.
+func (c *Client) TitleDetails(ctx context.Context, result ImdbSearchResult) (ImdbSearchResult, error) {
+	var details ImdbSearchResult
+	if strings.HasPrefix(result.Id, "tt") {
+		title, err := c.ImdbTitle(ctx, result.Id)
+		if err != nil {
+			return result, err
+		}
+		details = title
+	} else {
+		addon, err := c.metaAddon(ctx, result.StremioType(), result.Id)
+		if err != nil {
+			return result, err
+		}
+		meta, err := addon.Meta(ctx, result.StremioType(), result.Id)
+		if err != nil {
+			return result, err
+		}
+		details = meta.AsSearchResult()
+	}
+	return result.merge(details), nil
+}
+
+// merge overlays the non-empty fields of details.
+func (r ImdbSearchResult) merge(details ImdbSearchResult) ImdbSearchResult {
+	if r.Type == "" {
+		r.Type = details.Type
+	}
+	if r.AddonType == "" {
+		r.AddonType = details.AddonType
+	}
+	if details.PrimaryTitle != "" {
+		r.PrimaryTitle = details.PrimaryTitle
+	}
+	if details.OriginalTitle != "" {
+		r.OriginalTitle = details.OriginalTitle
+	}
+	if details.StartYear > 0 {
+		r.StartYear, r.EndYear = details.StartYear, details.EndYear
+	}
+	if details.Rating != nil {
+		r.Rating = details.Rating
+	}
+	if len(details.Genres) > 0 {
+		r.Genres = details.Genres
+	}
+	if details.RuntimeSeconds > 0 {
+		r.RuntimeSeconds = details.RuntimeSeconds
+	}
+	if details.Plot != "" {
+		r.Plot = details.Plot
+	}
+	return r
+}
+
+// Years formats the release years: "1999", "2008–2013", or "2019–" for a
+// series still running.
+func (r ImdbSearchResult) Years() string {
+	switch {
+	case r.StartYear == 0:
+		return ""
+	case r.EndYear > 0 && r.EndYear != r.StartYear:
+		return fmt.Sprintf("%d–%d", r.StartYear, r.EndYear)
+	case r.StremioType() == "series" && r.EndYear == 0:
+		return fmt.Sprintf("%d–", r.StartYear)
+	default:
+		return fmt.Sprint(r.StartYear)
+	}
+}
+
+// Runtime formats the runtime as "2h 15m", or "" when unknown.
+func (r ImdbSearchResult) Runtime() string {
+	minutes := r.RuntimeSeconds / 60
+	switch {
+	case minutes == 0:
+		return ""
+	case minutes < 60:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes%60 == 0:
+		return fmt.Sprintf("%dh", minutes/60)
+	default:
+		return fmt.Sprintf("%dh %dm", minutes/60, minutes%60)
+	}
+}
+
+// Trailer returns the YouTube id of the title's first trailer in the meta
+// addon's listing.
+func (c *Client) Trailer(ctx context.Context, result ImdbSearchResult) (string, error) {
+	contentType := result.StremioType()
+	addon, err := c.metaAddon(ctx, contentType, result.Id)
+	if err != nil {
+		return "", err
+	}
+	meta, err := addon.Meta(ctx, contentType, result.Id)
+	if err != nil {
+		return "", err
+	}
+	for _, t := range meta.TrailerStreams {
+		if t.YtId != "" {
+			return t.YtId, nil
+		}
+	}
+	return "", fmt.Errorf("no trailer for %s", result.PrimaryTitle)
+}
@@ -0,0 +1,88 @@
+// Package watchlist keeps the titles saved for later in a JSON file.
+package watchlist
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	apiutils "github.com/rshero/stremio-tui/utils"
+)
+
+// List is the watchlist, most recently added first.
+type List struct {
+	path   string
+	Titles []apiutils.ImdbSearchResult
+}
+
+// DefaultPath is $XDG_CONFIG_HOME/stremio-tui/watchlist.json.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "stremio-tui", "watchlist.json"), nil
+}
+
+// Open reads the watchlist at path, or DefaultPath when path is empty. A
+// missing file is an empty watchlist.
+func Open(path string) (*List, error) {
+	if path == "" {
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+	l := &List{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &l.Titles); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Has reports whether the title is on the watchlist.
+func (l *List) Has(id string) bool {
+	for _, t := range l.Titles {
+		if t.Id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Toggle adds the title, or removes it if it's already there, and saves the
+// list. added reports which happened.
+func (l *List) Toggle(title apiutils.ImdbSearchResult) (added bool, err error) {
+	for i, t := range l.Titles {
+		if t.Id == title.Id {
+			l.Titles = append(l.Titles[:i], l.Titles[i+1:]...)
+			return false, l.save()
+		}
+	}
+	l.Titles = append([]apiutils.ImdbSearchResult{title}, l.Titles...)
+	return true, l.save()
+}
+
+func (l *List) save() error {
+	data, err := json.MarshalIndent(l.Titles, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return err
+	}
+	// Write to a temp file first so a crash can't leave half a watchlist
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.path)
+}